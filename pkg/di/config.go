@@ -0,0 +1,508 @@
+package di
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/ns-go/di/internal/utils"
+)
+
+// ConfigTagKey is the struct tag key used to mark fields filled from the
+// configuration of the container.
+const ConfigTagKey = "di.config"
+
+// ConfigSource provides configuration values by dotted key, such as "db.host".
+// Keys are case-insensitive.
+type ConfigSource interface {
+	Lookup(key string) (string, bool)
+}
+
+// mapSource is a ConfigSource backed by a flattened map.
+type mapSource map[string]string
+
+func (s mapSource) Lookup(key string) (string, bool) {
+	value, ok := s[strings.ToLower(key)]
+	return value, ok
+}
+
+// NewMapSource returns a ConfigSource holding values in memory. Nested maps
+// are flattened into dotted keys and slices of values are joined with commas.
+func NewMapSource(values map[string]any) ConfigSource {
+	s := make(mapSource)
+	flattenConfig(s, "", values)
+	return s
+}
+
+// flattenConfig stores the values of a decoded JSON document, or any similar
+// tree of maps and slices, under dotted keys.
+func flattenConfig(s mapSource, prefix string, value any) {
+	flattenValue(s, prefix, reflect.ValueOf(value))
+}
+
+func flattenValue(s mapSource, prefix string, v reflect.Value) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			flattenValue(s, joinKey(prefix, fmt.Sprint(iter.Key().Interface())), iter.Value())
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			s[strings.ToLower(prefix)] = fmt.Sprintf("%s", v.Interface())
+			return
+		}
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			el := v.Index(i)
+			flattenValue(s, joinKey(prefix, strconv.Itoa(i)), el)
+			if !isConfigMap(el) {
+				items = append(items, formatConfigValue(el))
+			}
+		}
+		if len(items) == v.Len() {
+			s[strings.ToLower(prefix)] = strings.Join(items, ",")
+		}
+	default:
+		s[strings.ToLower(prefix)] = formatConfigValue(v)
+	}
+}
+
+// formatConfigValue formats a scalar config value. Floats are written without
+// an exponent, so that they can be parsed back into integer fields.
+func formatConfigValue(v reflect.Value) string {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// isConfigMap reports whether v holds a map, which is flattened under its own
+// keys instead of being listed in the value of its slice.
+func isConfigMap(v reflect.Value) bool {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Map
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	if key == "" {
+		return prefix
+	}
+	return prefix + "." + key
+}
+
+// envSource is a ConfigSource backed by environment variables.
+type envSource struct {
+	prefix string
+}
+
+// NewEnvSource returns a ConfigSource reading environment variables. The key
+// "db.host" is read from DB_HOST, or from PREFIX_DB_HOST if prefix is not
+// empty.
+func NewEnvSource(prefix string) ConfigSource {
+	return envSource{prefix: prefix}
+}
+
+func (s envSource) Lookup(key string) (string, bool) {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if s.prefix != "" {
+		name = strings.ToUpper(s.prefix) + "_" + name
+	}
+	return os.LookupEnv(name)
+}
+
+// ReloadableSource is a ConfigSource whose values can change while the
+// process runs. The container registers a callback with OnReload to republish
+// OptionsMonitor values when the source reloads.
+type ReloadableSource interface {
+	ConfigSource
+	OnReload(fn func())
+}
+
+// JSONFileSource is a ConfigSource reading a JSON file, which can be watched
+// for changes with Watch.
+type JSONFileSource struct {
+	path string
+
+	mu        sync.RWMutex
+	values    mapSource
+	data      []byte
+	listeners []func()
+}
+
+// NewJSONFileSource returns a ConfigSource holding the values of the JSON
+// file at path, flattened into dotted keys.
+func NewJSONFileSource(path string) (*JSONFileSource, error) {
+	s := &JSONFileSource{path: path}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads the file again and reports whether its content changed. The
+// callbacks registered with OnReload are called when it did.
+func (s *JSONFileSource) Reload() (bool, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	unchanged := s.values != nil && bytes.Equal(data, s.data)
+	s.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	// Numbers are kept as written, large integers would otherwise be
+	// formatted in exponent notation.
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return false, fmt.Errorf("cannot parse config file '%s': %w", s.path, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return false, fmt.Errorf("cannot parse config file '%s': unexpected data after the top-level value", s.path)
+	}
+	values := make(mapSource)
+	flattenConfig(values, "", doc)
+
+	s.mu.Lock()
+	s.values = values
+	s.data = data
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn()
+	}
+	return true, nil
+}
+
+// Watch polls the file every interval and reloads it when its content
+// changes. A file that cannot be read or parsed keeps the previous values.
+// The returned function stops watching.
+func (s *JSONFileSource) Watch(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.Reload()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// OnReload registers fn to be called every time the file content changes.
+func (s *JSONFileSource) OnReload(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *JSONFileSource) Lookup(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values.Lookup(key)
+}
+
+// configuration layers the config sources of a container. Sources added later
+// take precedence over earlier ones.
+type configuration struct {
+	sources  []ConfigSource
+	sections map[reflect.Type]string
+
+	mu       sync.Mutex
+	monitors map[reflect.Type]refresher
+}
+
+func newConfiguration() *configuration {
+	return &configuration{
+		sections: make(map[reflect.Type]string),
+		monitors: make(map[reflect.Type]refresher),
+	}
+}
+
+func (cfg *configuration) Lookup(key string) (string, bool) {
+	for i := len(cfg.sources) - 1; i >= 0; i-- {
+		if value, ok := cfg.sources[i].Lookup(key); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// WithConfigSources adds config sources to the container. Sources added later
+// take precedence over earlier ones.
+func WithConfigSources(sources ...ConfigSource) ContainerOption {
+	return func(c *Container) {
+		for _, s := range sources {
+			if s == nil {
+				continue
+			}
+			c.config.sources = append(c.config.sources, s)
+			if r, ok := s.(ReloadableSource); ok {
+				r.OnReload(c.config.reloaded)
+			}
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// convertConfig converts a configuration value to type t. Slices are read as
+// comma separated lists.
+func convertConfig(value string, t reflect.Type) (reflect.Value, error) {
+	result := reflect.New(t).Elem()
+
+	if t == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return result, err
+		}
+		result.SetInt(int64(d))
+		return result, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		result.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return result, err
+		}
+		result.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, t.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, t.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetFloat(f)
+	case reflect.Slice:
+		items := utils.FilterSlice(strings.Split(value, ","), func(s string) bool { return strings.TrimSpace(s) != "" })
+		slice := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			el, err := convertConfig(strings.TrimSpace(item), t.Elem())
+			if err != nil {
+				return result, err
+			}
+			slice.Index(i).Set(el)
+		}
+		result.Set(slice)
+	default:
+		return result, fmt.Errorf("unsupported config type '%s'", t)
+	}
+
+	return result, nil
+}
+
+// configTag is the parsed value of a di.config struct tag, written as
+// `di.config:"key[,optional]"`.
+type configTag struct {
+	key      string
+	optional bool
+}
+
+func parseConfigTag(value string) (configTag, error) {
+	key, opts, _ := strings.Cut(value, ",")
+	tag := configTag{key: strings.TrimSpace(key)}
+	if opts == "" {
+		return tag, nil
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		switch strings.TrimSpace(opt) {
+		case "optional":
+			tag.optional = true
+		default:
+			return tag, fmt.Errorf("unknown config option '%s'", opt)
+		}
+	}
+	return tag, nil
+}
+
+// injectConfig fills the fields of t tagged with ConfigTagKey in the struct
+// value points to. A key that is not configured fails unless the field is
+// optional.
+func (c *Container) injectConfig(value reflect.Value, t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, f := range reflect.VisibleFields(t) {
+		tagValue, ok := f.Tag.Lookup(ConfigTagKey)
+		if !ok {
+			continue
+		}
+		name := fieldPath(t, f.Index)
+		tag, err := parseConfigTag(tagValue)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", name, err)
+		}
+		if tag.key == "" {
+			return fmt.Errorf("field '%s': config key is empty", name)
+		}
+
+		raw, ok := c.config.Lookup(tag.key)
+		if !ok {
+			if tag.optional {
+				continue
+			}
+			return fmt.Errorf("field '%s': config key '%s' not found", name, tag.key)
+		}
+		val, err := convertConfig(raw, f.Type)
+		if err != nil {
+			return fmt.Errorf("field '%s': config key '%s': %w", name, tag.key, err)
+		}
+		settableField(value, f.Index).Set(val)
+	}
+
+	return nil
+}
+
+// sectionFieldKey returns the config key of a field of a struct bound to
+// section, and whether the field is skipped.
+func sectionFieldKey(f reflect.StructField, section string) (string, bool, error) {
+	if f.Anonymous {
+		return section, false, nil
+	}
+
+	key := strings.ToLower(f.Name)
+	if tagValue, ok := f.Tag.Lookup(ConfigTagKey); ok {
+		tag, err := parseConfigTag(tagValue)
+		if err != nil {
+			return "", false, fmt.Errorf("field '%s': %w", f.Name, err)
+		}
+		if tag.key == "-" {
+			return "", true, nil
+		}
+		if tag.key != "" {
+			key = tag.key
+		}
+	}
+	return joinKey(section, key), false, nil
+}
+
+// bind fills the struct value points to from the config section. A
+// field is read from the key named by its ConfigTagKey tag, or by its name in
+// lower case, relative to the section. Nested structs are read from nested
+// sections, embedded structs from the same section, and keys that are not
+// configured leave their field untouched.
+func (cfg *configuration) bind(value reflect.Value, section string) error {
+	t := value.Type().Elem()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, skip, err := sectionFieldKey(f, section)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		field := value.Elem().Field(i)
+		field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+
+		if f.Type.Kind() == reflect.Struct {
+			if err := cfg.bind(field.Addr(), key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := cfg.Lookup(key)
+		if !ok {
+			continue
+		}
+		val, err := convertConfig(raw, f.Type)
+		if err != nil {
+			return fmt.Errorf("config key '%s': %w", key, err)
+		}
+		field.Set(val)
+	}
+
+	return nil
+}
+
+// BindConfig registers TConfig as a singleton filled from the config section
+// of the container, for example "db" for the keys "db.host" and "db.port".
+func BindConfig[TConfig any](c *Container, section string, opts ...RegisterOption) error {
+	t := reflect.TypeOf(new(TConfig)).Elem()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind config to '%s', type is not a struct", t)
+	}
+	if c.scoped {
+		return errScopeRegistration
+	}
+	if c.typeItems[t] != nil {
+		return fmt.Errorf("type '%s' is already registered", t.Name())
+	}
+
+	c.config.sections[t] = section
+	c.typeItems[t] = c.newItemDescriptor(&ItemDescriptor{
+		itemType: t,
+		lifetime: Singleton,
+		construct: func(c *Container) (*reflect.Value, error) {
+			value, err := c.config.read(t)
+			if err != nil {
+				return nil, err
+			}
+			return &value, nil
+		},
+	}, opts)
+	return nil
+}
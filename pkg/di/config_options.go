@@ -0,0 +1,192 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// refresher is implemented by option monitors, which re-read their section
+// when the configuration reloads.
+type refresher interface {
+	refresh(cfg *configuration)
+}
+
+// section returns the config section t is bound to with BindConfig.
+func (cfg *configuration) section(t reflect.Type) (string, error) {
+	section, ok := cfg.sections[t]
+	if !ok {
+		return "", fmt.Errorf("type '%s' is not bound to a config section", t)
+	}
+	return section, nil
+}
+
+// read builds a new value of t from the config section it is bound to and
+// validates it.
+func (cfg *configuration) read(t reflect.Type) (reflect.Value, error) {
+	section, err := cfg.section(t)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	value := reflect.New(t)
+	if err := cfg.bind(value, section); err != nil {
+		return reflect.Value{}, err
+	}
+	if err := validateConfig(value, section); err != nil {
+		return reflect.Value{}, err
+	}
+	return value, nil
+}
+
+// reloaded republishes the values of every monitor after a source reloaded.
+func (cfg *configuration) reloaded() {
+	cfg.mu.Lock()
+	monitors := make([]refresher, 0, len(cfg.monitors))
+	for _, m := range cfg.monitors {
+		monitors = append(monitors, m)
+	}
+	cfg.mu.Unlock()
+
+	for _, m := range monitors {
+		m.refresh(cfg)
+	}
+}
+
+// snapshots caches the config values read once per container.
+type snapshots struct {
+	mu     sync.Mutex
+	values map[reflect.Type]reflect.Value
+}
+
+// snapshot returns the value of t read from the configuration once for the
+// container.
+func (c *Container) snapshot(t reflect.Type) (reflect.Value, error) {
+	c.snapshots.mu.Lock()
+	defer c.snapshots.mu.Unlock()
+
+	if value, ok := c.snapshots.values[t]; ok {
+		return value, nil
+	}
+	value, err := c.config.read(t)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	c.snapshots.values[t] = value
+	return value, nil
+}
+
+// Options holds the config section T was bound to with BindConfig, as read
+// when its singleton was created. Inject it into a field tagged with
+// di.inject.
+type Options[T any] struct {
+	value *T
+}
+
+func (o *Options[T]) bind(c *Container, name string) error {
+	value, err := Resolve[T](c)
+	if err != nil {
+		return err
+	}
+	o.value = value
+	return nil
+}
+
+// Value returns the config section.
+func (o *Options[T]) Value() *T {
+	return o.value
+}
+
+// OptionsSnapshot holds the config section T was bound to with BindConfig,
+// read once per scope, so that everything resolved from a scope sees the same
+// values even if the configuration reloads meanwhile.
+type OptionsSnapshot[T any] struct {
+	value *T
+}
+
+func (o *OptionsSnapshot[T]) bind(c *Container, name string) error {
+	value, err := c.snapshot(reflect.TypeOf(new(T)).Elem())
+	if err != nil {
+		return err
+	}
+	o.value = value.Interface().(*T)
+	return nil
+}
+
+// Value returns the config section as read for the scope.
+func (o *OptionsSnapshot[T]) Value() *T {
+	return o.value
+}
+
+// OptionsMonitor holds the current value of the config section T was bound to
+// with BindConfig and notifies listeners when a ReloadableSource changes it.
+type OptionsMonitor[T any] struct {
+	m *monitor[T]
+}
+
+type monitor[T any] struct {
+	t         reflect.Type
+	mu        sync.RWMutex
+	value     *T
+	listeners []func(*T)
+}
+
+func (o *OptionsMonitor[T]) bind(c *Container, name string) error {
+	t := reflect.TypeOf(new(T)).Elem()
+	cfg := c.config
+
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	if m, ok := cfg.monitors[t]; ok {
+		o.m = m.(*monitor[T])
+		return nil
+	}
+
+	value, err := cfg.read(t)
+	if err != nil {
+		return err
+	}
+	m := &monitor[T]{t: t, value: value.Interface().(*T)}
+	cfg.monitors[t] = m
+	o.m = m
+	return nil
+}
+
+func (m *monitor[T]) refresh(cfg *configuration) {
+	value, err := cfg.read(m.t)
+	if err != nil {
+		// Keep the previous value when the new configuration is invalid.
+		return
+	}
+	current := value.Interface().(*T)
+
+	m.mu.Lock()
+	m.value = current
+	listeners := m.listeners
+	m.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(current)
+	}
+}
+
+// Value returns the current value of the config section.
+func (o *OptionsMonitor[T]) Value() *T {
+	if o.m == nil {
+		return nil
+	}
+	o.m.mu.RLock()
+	defer o.m.mu.RUnlock()
+	return o.m.value
+}
+
+// OnChange registers fn to be called with the new value every time the config
+// section is reloaded.
+func (o *OptionsMonitor[T]) OnChange(fn func(*T)) {
+	if o.m == nil || fn == nil {
+		return
+	}
+	o.m.mu.Lock()
+	defer o.m.mu.Unlock()
+	o.m.listeners = append(o.m.listeners, fn)
+}
@@ -0,0 +1,158 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateTagKey is the struct tag key holding the validation rules of the
+// fields of a config section, written as `validate:"rule[=arg],..."`. The
+// rules are:
+//
+//	required   the value must not be the zero value
+//	min=n      numbers must be at least n, strings, slices and maps must
+//	           have at least n elements, durations must be at least n
+//	max=n      like min, for the upper bound
+//	oneof=a|b  the value must be one of the listed values
+const ValidateTagKey = "validate"
+
+// Validator is implemented by config sections that check themselves after
+// their fields have been validated.
+type Validator interface {
+	Validate() error
+}
+
+// FieldViolation describes a config value that breaks a validation rule.
+type FieldViolation struct {
+	Key     string
+	Message string
+}
+
+func (v FieldViolation) String() string {
+	return v.Key + ": " + v.Message
+}
+
+// ValidationError reports every violation found in a config section.
+type ValidationError struct {
+	Section    string
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return fmt.Sprintf("invalid config section '%s': %s", e.Section, strings.Join(messages, "; "))
+}
+
+// validateConfig checks the struct value points to, bound to section, against
+// the rules of its validation tags and its Validator implementations.
+func validateConfig(value reflect.Value, section string) error {
+	var violations []FieldViolation
+	collectViolations(value, section, &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Section: section, Violations: violations}
+}
+
+func collectViolations(value reflect.Value, section string, violations *[]FieldViolation) {
+	t := value.Type().Elem()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, skip, err := sectionFieldKey(f, section)
+		if err != nil || skip {
+			continue
+		}
+
+		// Bound fields may be unexported, read them as cfg.bind sets them.
+		field := exposed(value.Elem().Field(i))
+		if rules, ok := f.Tag.Lookup(ValidateTagKey); ok {
+			for _, rule := range strings.Split(rules, ",") {
+				if msg := checkRule(field, strings.TrimSpace(rule)); msg != "" {
+					*violations = append(*violations, FieldViolation{Key: key, Message: msg})
+				}
+			}
+		}
+
+		if f.Type.Kind() == reflect.Struct {
+			collectViolations(field.Addr(), key, violations)
+		}
+	}
+
+	if value.CanInterface() {
+		if v, ok := value.Interface().(Validator); ok {
+			if err := v.Validate(); err != nil {
+				*violations = append(*violations, FieldViolation{Key: section, Message: err.Error()})
+			}
+		}
+	}
+}
+
+// checkRule returns the message of the violation of rule by field, or an
+// empty string.
+func checkRule(field reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+
+	switch name {
+	case "":
+		return ""
+	case "required":
+		if field.IsZero() {
+			return "is required"
+		}
+	case "min", "max":
+		n, size, err := ruleSize(field, arg)
+		if err != nil {
+			return fmt.Sprintf("invalid rule '%s': %v", rule, err)
+		}
+		if name == "min" && size < n {
+			return fmt.Sprintf("must be at least %s", arg)
+		}
+		if name == "max" && size > n {
+			return fmt.Sprintf("must be at most %s", arg)
+		}
+	case "oneof":
+		value := fmt.Sprint(field.Interface())
+		for _, option := range strings.Split(arg, "|") {
+			if value == option {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(arg, "|", ", "))
+	default:
+		return fmt.Sprintf("unknown rule '%s'", name)
+	}
+	return ""
+}
+
+// ruleSize returns the bound arg of a min or max rule and the size of field it
+// is compared to.
+func ruleSize(field reflect.Value, arg string) (float64, float64, error) {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(arg)
+		return float64(d), float64(field.Int()), err
+	}
+
+	n, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return n, float64(field.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return n, float64(field.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return n, field.Float(), nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return n, float64(field.Len()), nil
+	}
+	return 0, 0, fmt.Errorf("type '%s' has no size", field.Type())
+}
@@ -0,0 +1,95 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypeConfig describes how the fields of T are injected, for types whose
+// struct tags cannot be changed, such as types of third party libraries. A
+// field configured for a type takes precedence over its struct tag.
+//
+//	di.Configure[ThirdParty](c).Field("Logger").Named("app-log").Field("Client")
+//
+// The methods of TypeConfig panic if they refer to a field T does not have,
+// which is a programming error found the first time the code runs.
+type TypeConfig[T any] struct {
+	c     *Container
+	t     reflect.Type
+	field string
+}
+
+// Configure returns the injection configuration of T in the container c,
+// shared with every scope of c.
+func Configure[T any](c *Container) *TypeConfig[T] {
+	t := reflect.TypeOf(new(T)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("cannot configure injection of '%s', type is not a struct", t))
+	}
+	if c.mappings[t] == nil {
+		c.mappings[t] = make(map[string]injectTag)
+	}
+	return &TypeConfig[T]{c: c, t: t}
+}
+
+// Field marks the named field for injection by its type. The following calls
+// to Named, Optional, Lazy and Group apply to this field.
+func (tc *TypeConfig[T]) Field(name string) *TypeConfig[T] {
+	if _, ok := tc.t.FieldByName(name); !ok {
+		panic(fmt.Errorf("field '%s' not found on type '%s'", name, tc.t))
+	}
+	tc.field = name
+	tc.c.mappings[tc.t][name] = injectTag{}
+	return tc
+}
+
+// Named makes the current field resolve the item registered by name.
+func (tc *TypeConfig[T]) Named(name string) *TypeConfig[T] {
+	tag := tc.current()
+	tag.name = name
+	tc.c.mappings[tc.t][tc.field] = tag
+	return tc
+}
+
+// Optional leaves the current field empty when nothing is registered.
+func (tc *TypeConfig[T]) Optional() *TypeConfig[T] {
+	tag := tc.current()
+	tag.optional = true
+	tc.c.mappings[tc.t][tc.field] = tag
+	return tc
+}
+
+// Lazy makes the current field, of type func() T or func() (T, error),
+// resolve its item when it is first called.
+func (tc *TypeConfig[T]) Lazy() *TypeConfig[T] {
+	tag := tc.current()
+	tag.lazy = true
+	tc.c.mappings[tc.t][tc.field] = tag
+	return tc
+}
+
+// Group fills the current slice field with every item assignable to its
+// element type.
+func (tc *TypeConfig[T]) Group() *TypeConfig[T] {
+	tag := tc.current()
+	tag.group = true
+	tc.c.mappings[tc.t][tc.field] = tag
+	return tc
+}
+
+// Skip excludes the named field from injection, even if it has a struct tag.
+func (tc *TypeConfig[T]) Skip(name string) *TypeConfig[T] {
+	if _, ok := tc.t.FieldByName(name); !ok {
+		panic(fmt.Errorf("field '%s' not found on type '%s'", name, tc.t))
+	}
+	tc.field = ""
+	tc.c.mappings[tc.t][name] = injectTag{skip: true}
+	return tc
+}
+
+func (tc *TypeConfig[T]) current() injectTag {
+	if tc.field == "" {
+		panic(fmt.Errorf("no field selected on type '%s', call Field first", tc.t))
+	}
+	return tc.c.mappings[tc.t][tc.field]
+}
@@ -1,13 +1,12 @@
 package di
 
 import (
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"reflect"
-	"regexp"
 	"unsafe"
-
-	"github.com/ns-go/di/internal/utils"
 )
 
 // Container is the main dependency injection container.
@@ -16,18 +15,45 @@ type Container struct {
 	typeItems       map[reflect.Type]*ItemDescriptor
 	scoped          bool
 	masterContainer *Container
+	parent          *Container
+	ctx             context.Context
+	life            *lifecycle
+	tagKey          string
+	mappings        map[reflect.Type]map[string]injectTag
+	config          *configuration
+	snapshots       *snapshots
+	flags           *flag.FlagSet
+	secrets         []SecretProvider
+	expiries        *expiries
+	lifetimes       *lifetimes
+	graph           *resolveGraph
+	origin          *Container
 }
 
+// contextType is the type under which the context of a scope is registered.
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 // injectFieldInfo holds information about a struct field that requires injection.
 type injectFieldInfo struct {
-	fieldName string
-	fieldType reflect.Type
-	itemName  *string
+	fieldName  string
+	fieldIndex []int
+	fieldType  reflect.Type
+	itemName   *string
+	optional   bool
+	lazy       bool
+	group      bool
+	tagErr     error
 }
 
 // createInstance creates an instance of an item registered in the container.
 func (c *Container) createInstance(d *ItemDescriptor) (*reflect.Value, error) {
-	tagExp := regexp.MustCompile("di.inject:")
+	// Items with their own constructor, such as config sections, are built
+	// without field injection.
+	if d.construct != nil {
+		return d.construct(c)
+	}
+
+	defer c.building(d)()
 
 	var value reflect.Value
 
@@ -54,114 +80,98 @@ func (c *Container) createInstance(d *ItemDescriptor) (*reflect.Value, error) {
 		value = reflect.New(d.itemType)
 	}
 
-	typeOfInstance := d.itemType
-
-	numField := typeOfInstance.NumField()
-	fields := make([]reflect.StructField, numField)
-
-	for i := 0; i < numField; i++ {
-		fields[i] = typeOfInstance.Field(i)
+	if err := c.inject(value, d.itemType, d.injectMethods); err != nil {
+		return nil, err
 	}
 
-	// Filter the struct fields to include only those with the "di.inject" tag.
-	fields = utils.FilterSlice(fields, func(f reflect.StructField) bool { return tagExp.MatchString(string(f.Tag)) })
+	return &value, nil
+}
 
-	// Map the filtered fields to injectFieldInfo structs.
-	injectFields := utils.MapSlice(fields, func(f reflect.StructField) injectFieldInfo {
-		result := injectFieldInfo{}
-		result.fieldName = f.Name
-		result.fieldType = f.Type
-		tagName := f.Tag.Get("di.inject")
-		if tagName != "" {
-			result.itemName = &tagName
-		}
-		return result
-	})
+// injectFields sets the fields of t tagged for injection in the struct value
+// points to.
+func (c *Container) injectFields(value reflect.Value, t reflect.Type) error {
+	injectFields := c.injectionFields(t)
 
 	for _, f := range injectFields {
+		if f.tagErr != nil {
+			return fmt.Errorf("field '%s': %w", f.fieldName, f.tagErr)
+		}
 
-		// Check if the field type is a pointer.
-		if f.fieldType.Kind() != reflect.Pointer {
-			return nil, errors.New("type of injection field allow only pointer")
+		val, err := c.resolveField(f)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", f.fieldName, err)
 		}
 
-		var des *ItemDescriptor
-		var finstance *reflect.Value
-		var err error
+		// Set the field value to the resolved instance.
+		settableField(value, f.fieldIndex).Set(val)
+	}
 
-		// Resolve the dependency either by name or by type.
-		if f.itemName != nil && *f.itemName != "" {
-			des = c.namedItems[*f.itemName]
-			finstance, err = c.resolveByName(*f.itemName)
-			if err != nil {
-				return nil, err
-			}
+	return nil
+}
 
-		} else {
+func itemNameOf(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
 
-			fieldType := f.fieldType
+// isRegistered reports whether an item is registered by name, or by type if
+// name is empty.
+func (c *Container) isRegistered(t reflect.Type, name string) bool {
+	if name != "" {
+		return c.namedItems[name] != nil
+	}
+	return c.typeItems[t] != nil
+}
 
-			if fieldType.Kind() == reflect.Ptr {
-				fieldType = fieldType.Elem()
-			}
-			des = c.typeItems[fieldType]
-			finstance, err = c.resolveByType(fieldType)
-			if err != nil {
-				return nil, err
+// settableField returns the field at index of the struct value points to,
+// made settable even if the field is unexported. Nil pointers to embedded
+// structs on the way are allocated.
+func settableField(value reflect.Value, index []int) reflect.Value {
+	f := value
+	for _, x := range index {
+		if f.Kind() == reflect.Pointer {
+			if f.IsNil() {
+				f.Set(reflect.New(f.Type().Elem()))
 			}
-
-		}
-
-		fieldType := f.fieldType
-
-		if fieldType.Elem() != des.itemType {
-			return nil, fmt.Errorf("field '%s' type not match to item type '%s'", f.fieldName, des.itemType)
-		}
-
-		var f1 reflect.Value
-		if value.Kind() == reflect.Pointer {
-			f1 = value.Elem().FieldByName(f.fieldName)
-		} else {
-			f1 = value.FieldByName(f.fieldName)
+			f = f.Elem()
 		}
-
-		// Set the field value to the resolved instance.
-		x := reflect.NewAt(f1.Type(), unsafe.Pointer(f1.UnsafeAddr())).Elem()
-		x.Set((*finstance))
-
+		f = f.Field(x)
+		f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
 	}
-
-	return &value, nil
+	return f
 }
 
 func (c *Container) resolveItemValue(d *ItemDescriptor) (*reflect.Value, error) {
-	if d.lifetime == Scoped && !c.scoped {
-		return nil, errors.New("cannot resolve scoped item with none scoped container")
+	if c.life.isDisposed() {
+		return nil, errors.New("cannot resolve item from disposed container")
 	}
 
-	if d.lifetime == Singleton || d.lifetime == Scoped { //Scoped items are cloned from  master container
-		if d.name != nil { //registered instant by name
-			return d.instance, nil
-		} else {
-			if d.instance == nil {
-				if ins, err := c.createInstance(d); err != nil {
-					return nil, err
-				} else {
-					d.instance = ins
-				}
-
-				return d.instance, nil
-			} else {
-				return d.instance, nil
-			}
+	manager, err := c.lifetimes.manager(d.lifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.injectOnResolve {
+		if err := c.injectRegistered(d); err != nil {
+			return nil, err
 		}
-	} else {
-		if ins, err := c.createInstance(d); err != nil {
+	}
+
+	ins, err := manager.Resolve(Resolution{c: c, item: d}, func() (any, error) {
+		ins, err := c.createInstance(d)
+		if ins == nil || err != nil {
 			return nil, err
-		} else {
-			return ins, nil
 		}
+		return ins.Interface(), nil
+	})
+	if ins == nil || err != nil {
+		return nil, err
 	}
+
+	value := reflect.ValueOf(ins)
+	return &value, nil
 }
 
 // resolveByName resolves an item from the container by name.
@@ -170,7 +180,9 @@ func (c *Container) resolveByName(name string) (*reflect.Value, error) {
 	if des == nil {
 		return nil, fmt.Errorf("no any instance register by name '%s'", name)
 	}
-	val, err := c.resolveItemValue(des)
+	g, done := c.withGraph()
+	defer done()
+	val, err := g.resolveItemValue(des)
 	return val, err
 }
 
@@ -179,7 +191,9 @@ func (c *Container) ResolveByName(name string) (any, error) {
 	if des == nil {
 		return nil, fmt.Errorf("no any instance register by name '%s'", name)
 	}
-	val, err := c.resolveItemValue(des)
+	g, done := c.withGraph()
+	defer done()
+	val, err := g.resolveItemValue(des)
 
 	if err != nil {
 		return nil, err
@@ -197,7 +211,9 @@ func (c *Container) resolveByType(t reflect.Type) (*reflect.Value, error) {
 		return nil, fmt.Errorf("type '%s' not registered", t.Name())
 	}
 
-	val, err := c.resolveItemValue(des)
+	g, done := c.withGraph()
+	defer done()
+	val, err := g.resolveItemValue(des)
 	return val, err
 }
 
@@ -207,7 +223,9 @@ func (c *Container) ResolveByType(t reflect.Type) (any, error) {
 		return nil, fmt.Errorf("type '%s' not registered", t.Name())
 	}
 
-	val, err := c.resolveItemValue(des)
+	g, done := c.withGraph()
+	defer done()
+	val, err := g.resolveItemValue(des)
 	if err != nil {
 		return nil, err
 	}
@@ -216,13 +234,42 @@ func (c *Container) ResolveByType(t reflect.Type) (any, error) {
 	return val1, err
 }
 
+// NewScope creates a scoped container. A scope created from the master
+// container has a background context, a nested scope inherits the context of
+// its parent.
 func (c *Container) NewScope() (*Container, error) {
-	if c.scoped {
-		return nil, errors.New("cannot create scope from none-master container")
+	return c.NewScopeContext(c.Context())
+}
+
+// NewScopeContext creates a scoped container bound to ctx. The context is
+// registered in the scope and can be injected as context.Context. The scope
+// is disposed automatically once ctx is done, or when its parent scope is
+// disposed.
+func (c *Container) NewScopeContext(ctx context.Context) (*Container, error) {
+	if c.life.isDisposed() {
+		return nil, errors.New("cannot create scope from disposed container")
 	}
+	if ctx == nil {
+		return nil, errors.New("cannot create scope with nil context")
+	}
+	c = c.withoutGraph()
 	childContainer := Container{}
 	childContainer.masterContainer = c
+	if c.scoped {
+		childContainer.masterContainer = c.masterContainer
+		childContainer.parent = c
+	}
 	childContainer.scoped = true
+	childContainer.ctx = ctx
+	childContainer.life = newLifecycle()
+	childContainer.tagKey = c.tagKey
+	childContainer.mappings = c.mappings
+	childContainer.config = c.config
+	childContainer.flags = c.flags
+	childContainer.secrets = c.secrets
+	childContainer.expiries = c.expiries
+	childContainer.lifetimes = c.lifetimes
+	childContainer.snapshots = &snapshots{values: make(map[reflect.Type]reflect.Value)}
 	nameditems := make(map[string]*ItemDescriptor)
 	typeitems := make(map[reflect.Type]*ItemDescriptor)
 
@@ -231,21 +278,30 @@ func (c *Container) NewScope() (*Container, error) {
 	}
 
 	for k, el := range c.typeItems {
-		if el.lifetime == Singleton {
+		if el.provided || c.lifetimes.shared(el.lifetime) {
 			typeitems[k] = el
 		} else {
-			typeitems[k] = &ItemDescriptor{
-				name:     el.name,
-				itemType: el.itemType,
-				lifetime: el.lifetime,
-				instance: nil,
-				factory:  el.factory,
-			}
+			typeitems[k] = el.scopedCopy()
 		}
 	}
 
 	childContainer.namedItems = nameditems
 	childContainer.typeItems = typeitems
+	childContainer.provide(contextType, ctx)
+
+	if c.scoped {
+		c.life.track(reflect.ValueOf(&childContainer))
+	}
+
+	if done := ctx.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+				childContainer.Dispose()
+			case <-childContainer.life.done:
+			}
+		}()
+	}
 
 	return &childContainer, nil
 }
@@ -254,6 +310,21 @@ func (c *Container) MasterContainer() *Container {
 	return c.masterContainer
 }
 
+// Parent returns the scope a nested scope was created from, or nil for the
+// master container and scopes created from it.
+func (c *Container) Parent() *Container {
+	return c.parent
+}
+
+// Context returns the context the container is bound to. The master
+// container is bound to context.Background.
+func (c *Container) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 func ResolveByName[TResult any](c *Container, name string) (*TResult, error) {
 	val, err := c.ResolveByName(name)
 	if err != nil {
@@ -273,7 +344,16 @@ func Resolve[TResult any](c *Container) (*TResult, error) {
 	return result, err
 }
 
-func (c *Container) RegisterType(t reflect.Type, lifetime Lifetime, safe bool) error {
+func (c *Container) RegisterType(t reflect.Type, lifetime Lifetime, safe bool, opts ...RegisterOption) error {
+	if c.scoped {
+		err := errScopeRegistration
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
 	if t.Kind() == reflect.Ptr {
 		err := errors.New("cannot register type of pointer")
 		if safe {
@@ -293,11 +373,20 @@ func (c *Container) RegisterType(t reflect.Type, lifetime Lifetime, safe bool) e
 		}
 	}
 
-	c.typeItems[t] = &ItemDescriptor{itemType: t, lifetime: lifetime}
+	c.typeItems[t] = c.newItemDescriptor(&ItemDescriptor{itemType: t, lifetime: lifetime}, opts)
 	return nil
 }
 
-func (c *Container) RegisterInstance(value any, safe bool) error {
+func (c *Container) RegisterInstance(value any, safe bool, opts ...RegisterOption) error {
+	if c.scoped {
+		err := errScopeRegistration
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
 	if value == nil {
 		err := errors.New("cannot register nil")
 		if safe {
@@ -325,17 +414,26 @@ func (c *Container) RegisterInstance(value any, safe bool) error {
 
 	if t.Kind() == reflect.Pointer {
 		ptr := reflect.ValueOf(value)
-		c.typeItems[_t] = &ItemDescriptor{itemType: _t, lifetime: Singleton, instance: &ptr}
+		c.typeItems[_t] = c.newItemDescriptor(&ItemDescriptor{itemType: _t, lifetime: Singleton, instance: &ptr}, opts)
 	} else {
 		ptr := reflect.New(_t)
 		val := reflect.ValueOf(value)
 		ptr.Elem().Set(val)
-		c.typeItems[_t] = &ItemDescriptor{itemType: _t, lifetime: Singleton, instance: &ptr}
+		c.typeItems[_t] = c.newItemDescriptor(&ItemDescriptor{itemType: _t, lifetime: Singleton, instance: &ptr}, opts)
 	}
 	return nil
 }
 
-func (c *Container) RegisterByName(name string, value any, safe bool) error {
+func (c *Container) RegisterByName(name string, value any, safe bool, opts ...RegisterOption) error {
+	if c.scoped {
+		err := errScopeRegistration
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
 	t := reflect.TypeOf(value)
 
 	des := c.namedItems[name]
@@ -350,18 +448,18 @@ func (c *Container) RegisterByName(name string, value any, safe bool) error {
 
 	if t.Kind() == reflect.Pointer {
 		ptr := reflect.ValueOf(value)
-		c.namedItems[name] = &ItemDescriptor{itemType: t.Elem(), lifetime: Singleton, name: &name, instance: &ptr}
+		c.namedItems[name] = c.newItemDescriptor(&ItemDescriptor{itemType: t.Elem(), lifetime: Singleton, name: &name, instance: &ptr}, opts)
 	} else {
 		ptr := reflect.New(t)
 		val := reflect.ValueOf(value)
 		ptr.Elem().Set(val)
-		c.namedItems[name] = &ItemDescriptor{itemType: t, lifetime: Singleton, name: &name, instance: &ptr}
+		c.namedItems[name] = c.newItemDescriptor(&ItemDescriptor{itemType: t, lifetime: Singleton, name: &name, instance: &ptr}, opts)
 	}
 
 	return nil
 }
 
-func (c *Container) RegisterFactory(t reflect.Type, lifetime Lifetime, factory ItemFactory, safe bool) error {
+func (c *Container) RegisterFactory(t reflect.Type, lifetime Lifetime, factory ItemFactory, safe bool, opts ...RegisterOption) error {
 	if factory == nil {
 		err := errors.New("factory could not be null")
 		if safe {
@@ -369,6 +467,15 @@ func (c *Container) RegisterFactory(t reflect.Type, lifetime Lifetime, factory I
 		}
 	}
 
+	if c.scoped {
+		err := errScopeRegistration
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
 	if t.Kind() == reflect.Ptr {
 		err := errors.New("cannot register type of pointer")
 		if safe {
@@ -388,51 +495,77 @@ func (c *Container) RegisterFactory(t reflect.Type, lifetime Lifetime, factory I
 		}
 	}
 
-	c.typeItems[t] = &ItemDescriptor{itemType: t, lifetime: lifetime, factory: factory}
+	c.typeItems[t] = c.newItemDescriptor(&ItemDescriptor{itemType: t, lifetime: lifetime, factory: factory}, opts)
 	return nil
 }
 
-func RegisterScoped[T any](c *Container, safe bool) error {
+func RegisterScoped[T any](c *Container, safe bool, opts ...RegisterOption) error {
 	t := reflect.TypeOf(new(T)).Elem()
-	err := c.RegisterType(t, Scoped, safe)
+	err := c.RegisterType(t, Scoped, safe, opts...)
 	return err
 }
 
-func RegisterTransient[T any](c *Container, safe bool) error {
+func RegisterTransient[T any](c *Container, safe bool, opts ...RegisterOption) error {
 	t := reflect.TypeOf(new(T)).Elem()
-	err := c.RegisterType(t, Transient, safe)
+	err := c.RegisterType(t, Transient, safe, opts...)
 	return err
 }
 
-func RegisterSingleton[T any](c *Container, safe bool) error {
+func RegisterSingleton[T any](c *Container, safe bool, opts ...RegisterOption) error {
 	t := reflect.TypeOf(new(T)).Elem()
-	err := c.RegisterType(t, Singleton, safe)
+	err := c.RegisterType(t, Singleton, safe, opts...)
 	return err
 }
 
-func RegisterInstance[T any](c *Container, value *T, safe bool) error {
-	err := c.RegisterInstance(value, safe)
+func RegisterPooled[T any](c *Container, safe bool, opts ...RegisterOption) error {
+	t := reflect.TypeOf(new(T)).Elem()
+	err := c.RegisterType(t, Pooled, safe, opts...)
+	return err
+}
+
+func RegisterPerResolve[T any](c *Container, safe bool, opts ...RegisterOption) error {
+	t := reflect.TypeOf(new(T)).Elem()
+	err := c.RegisterType(t, PerResolve, safe, opts...)
 	return err
 }
 
-func RegisterByName(c *Container, name string, value any, safe bool) error {
-	err := c.RegisterByName(name, value, safe)
+func RegisterInstance[T any](c *Container, value *T, safe bool, opts ...RegisterOption) error {
+	err := c.RegisterInstance(value, safe, opts...)
 	return err
 }
 
-func RegisterFactory[T any](c *Container, lifetime Lifetime, factory func(Container) *T, safe bool) error {
+func RegisterByName(c *Container, name string, value any, safe bool, opts ...RegisterOption) error {
+	err := c.RegisterByName(name, value, safe, opts...)
+	return err
+}
+
+func RegisterFactory[T any](c *Container, lifetime Lifetime, factory func(Container) *T, safe bool, opts ...RegisterOption) error {
 	t := reflect.TypeOf(new(T)).Elem()
-	err := c.RegisterFactory(t, lifetime, func(c Container) any { return factory(c) }, safe)
+	err := c.RegisterFactory(t, lifetime, func(c Container) any { return factory(c) }, safe, opts...)
 
 	return err
 }
 
 // NewContainer creates a new dependency injection container.
-func NewContainer() *Container {
-	return &Container{
+func NewContainer(opts ...ContainerOption) *Container {
+	c := &Container{
 		namedItems:      make(map[string]*ItemDescriptor),
 		typeItems:       make(map[reflect.Type]*ItemDescriptor),
 		scoped:          false,
 		masterContainer: nil,
+		ctx:             context.Background(),
+		life:            newLifecycle(),
+		tagKey:          DefaultTagKey,
+		mappings:        make(map[reflect.Type]map[string]injectTag),
+		config:          newConfiguration(),
+		snapshots:       &snapshots{values: make(map[reflect.Type]reflect.Value)},
+		expiries:        &expiries{},
+		lifetimes:       newLifetimes(),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
@@ -0,0 +1,143 @@
+package di
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Disposable is implemented by items that hold resources which must be
+// released when the container that created them is disposed.
+type Disposable interface {
+	Dispose() error
+}
+
+// lifecycle holds the disposal state of a container. It is kept behind a
+// pointer so that copies of a Container share it.
+type lifecycle struct {
+	mu          sync.Mutex
+	disposables []any
+	pooled      []pooledInstance
+	disposed    bool
+	done        chan struct{}
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{done: make(chan struct{})}
+}
+
+// track records an instance created by the container so that it is disposed
+// together with the container.
+func (l *lifecycle) track(value reflect.Value) {
+	if !value.IsValid() || !value.CanInterface() {
+		return
+	}
+	instance := value.Interface()
+	switch instance.(type) {
+	case Disposable, io.Closer:
+	default:
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.disposables = append(l.disposables, instance)
+}
+
+// trackResolved records an instance created for one resolution, such as a
+// transient, so that the scope which resolved it disposes it. Instances
+// resolved from the master container are owned by the caller, since the
+// master container usually lives as long as the process.
+func (c *Container) trackResolved(value reflect.Value) {
+	if c.scoped {
+		c.life.track(value)
+	}
+}
+
+// untrack removes an instance recorded by track and reports whether it was
+// found.
+func (l *lifecycle) untrack(instance any) bool {
+	if !reflect.TypeOf(instance).Comparable() {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, d := range l.disposables {
+		if d == instance {
+			l.disposables = append(l.disposables[:i], l.disposables[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (l *lifecycle) isDisposed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.disposed
+}
+
+// owner returns the container which owns instances of the given item. Items
+// of a lifetime shared with scopes, such as singletons, belong to the master
+// container, everything else belongs to the container that created it.
+func (c *Container) owner(d *ItemDescriptor) *Container {
+	if c.masterContainer != nil && c.lifetimes.shared(d.lifetime) {
+		return c.masterContainer
+	}
+	return c
+}
+
+// Dispose releases every instance created by the container that implements
+// Disposable or io.Closer, in reverse order of creation, then lets the
+// lifetime managers release the instances they hold for the container, such
+// as returning the instances of Pooled items to their pool. Instances registered
+// with RegisterInstance or RegisterByName, and Transient or PerResolve
+// instances resolved from the master container, are owned by the caller and
+// are not disposed. Calling Dispose more than once has no effect.
+func (c *Container) Dispose() error {
+	c = c.withoutGraph()
+	l := c.life
+	l.mu.Lock()
+	if l.disposed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.disposed = true
+	disposables := l.disposables
+	l.disposables = nil
+	close(l.done)
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(disposables) - 1; i >= 0; i-- {
+		var err error
+		switch d := disposables[i].(type) {
+		case Disposable:
+			err = d.Dispose()
+		case io.Closer:
+			err = d.Close()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, c.lifetimes.dispose(c)...)
+
+	if c.parent != nil {
+		c.parent.life.untrack(c)
+	}
+
+	if c.masterContainer == nil {
+		errs = append(errs, c.expiries.flush()...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Disposed reports whether Dispose has been called on the container.
+func (c *Container) Disposed() bool {
+	return c.life.isDisposed()
+}
@@ -0,0 +1,130 @@
+package di
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ExpiresAfter makes the container rebuild a singleton or scoped item when it
+// is resolved more than ttl after its instance was created. The expired
+// instance is disposed once every resolution that received it is complete. It
+// has no effect on instances registered with RegisterInstance or
+// RegisterByName.
+func ExpiresAfter(ttl time.Duration) RegisterOption {
+	return func(d *ItemDescriptor) {
+		d.ttl = ttl
+	}
+}
+
+// expiry guards the instance of an item registered with ExpiresAfter.
+type expiry struct {
+	mu        sync.Mutex
+	createdAt time.Time
+	current   *generation
+}
+
+// newExpiry returns the expiry of an item with the given ttl, nil if the item
+// does not expire.
+func newExpiry(ttl time.Duration) *expiry {
+	if ttl <= 0 {
+		return nil
+	}
+	return &expiry{}
+}
+
+// generation is an instance of an item with a ttl, with the number of
+// resolutions in progress that received it.
+type generation struct {
+	instance any
+	users    int
+	expired  bool
+}
+
+// expiries collects the errors of disposing expired instances. It is shared
+// by a container and its scopes, and reported by the master container.
+type expiries struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// resolveExpiring returns the instance of an item with a ttl, creating a new
+// one when there is none or it has expired. The instance is held until the
+// top-level resolution of c is complete.
+func (c *Container) resolveExpiring(item *ItemDescriptor, create func() (any, error)) (any, error) {
+	e := item.expiry
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil && time.Since(e.createdAt) >= item.ttl {
+		old := e.current
+		e.current = nil
+		item.instance = nil
+		old.expired = true
+		if !c.owner(item).life.untrack(old.instance) {
+			// Nothing to dispose.
+			old.instance = nil
+		} else if old.users == 0 {
+			c.expiries.dispose(old.instance)
+			old.instance = nil
+		}
+	}
+
+	if e.current == nil {
+		ins, err := create()
+		if ins == nil || err != nil {
+			return nil, err
+		}
+		value := reflect.ValueOf(ins)
+		item.instance = &value
+		e.createdAt = time.Now()
+		e.current = &generation{instance: ins}
+		c.owner(item).life.track(value)
+	}
+
+	g := e.current
+	if c.graph != nil {
+		g.users++
+		c.graph.done = append(c.graph.done, func() { c.release(e, g) })
+	}
+	return g.instance, nil
+}
+
+// release ends the use of g by a resolution, disposing it if it has expired
+// and no other resolution uses it.
+func (c *Container) release(e *expiry, g *generation) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	g.users--
+	if g.expired && g.users == 0 && g.instance != nil {
+		c.expiries.dispose(g.instance)
+		g.instance = nil
+	}
+}
+
+// dispose disposes an expired instance, keeping the error for the master
+// container.
+func (e *expiries) dispose(instance any) {
+	var err error
+	switch d := instance.(type) {
+	case Disposable:
+		err = d.Dispose()
+	case io.Closer:
+		err = d.Close()
+	}
+	if err != nil {
+		e.mu.Lock()
+		e.errs = append(e.errs, err)
+		e.mu.Unlock()
+	}
+}
+
+// flush returns the errors of disposing expired instances.
+func (e *expiries) flush() []error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	errs := e.errs
+	e.errs = nil
+	return errs
+}
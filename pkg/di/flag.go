@@ -0,0 +1,173 @@
+package di
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FlagTagKey is the struct tag key used to mark fields filled from command
+// line flags.
+const FlagTagKey = "di.flag"
+
+// WithFlagSet sets the flag set that fields tagged with FlagTagKey are bound
+// to. The flags are defined on it by Validate, so Validate must be called
+// before the flag set is parsed.
+func WithFlagSet(fs *flag.FlagSet) ContainerOption {
+	return func(c *Container) {
+		c.flags = fs
+	}
+}
+
+// flagTag is the parsed value of a di.flag struct tag, written as
+//
+//	di.flag:"name[,default=value][,usage=text]"
+//
+// The usage option runs to the end of the tag and may contain commas.
+type flagTag struct {
+	name  string
+	value string
+	usage string
+}
+
+func parseFlagTag(value string) (flagTag, error) {
+	name, opts, _ := strings.Cut(value, ",")
+	tag := flagTag{name: strings.TrimSpace(name)}
+	if tag.name == "" {
+		return tag, fmt.Errorf("flag name is empty")
+	}
+
+	for opts != "" {
+		if usage, ok := strings.CutPrefix(strings.TrimLeft(opts, " "), "usage="); ok {
+			tag.usage = usage
+			break
+		}
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		key, val, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "default":
+			tag.value = val
+		default:
+			return tag, fmt.Errorf("unknown flag option '%s'", opt)
+		}
+	}
+
+	return tag, nil
+}
+
+// fieldFlag is a flag.Value accepting the values a field can be converted
+// from.
+type fieldFlag struct {
+	t     reflect.Type
+	value string
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *fieldFlag) Set(value string) error {
+	if _, err := convertConfig(value, f.t); err != nil {
+		return err
+	}
+	f.value = value
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be set without a value, as in -verbose.
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.t.Kind() == reflect.Bool
+}
+
+// defineFlags defines a flag on the flag set of the container for every field
+// of t tagged with FlagTagKey. A flag shared by several fields is defined once.
+func (c *Container) defineFlags(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, f := range reflect.VisibleFields(t) {
+		tagValue, ok := f.Tag.Lookup(FlagTagKey)
+		if !ok {
+			continue
+		}
+		name := fieldPath(t, f.Index)
+		tag, err := parseFlagTag(tagValue)
+		if err != nil {
+			return fmt.Errorf("field '%s' of type '%s': %w", name, t, err)
+		}
+		if c.flags == nil {
+			return fmt.Errorf("field '%s' of type '%s': no flag set configured, use WithFlagSet", name, t)
+		}
+
+		value := &fieldFlag{t: f.Type}
+		if tag.value != "" {
+			if err := value.Set(tag.value); err != nil {
+				return fmt.Errorf("field '%s' of type '%s': default of flag '%s': %w", name, t, tag.name, err)
+			}
+		}
+
+		// Fields sharing a flag complete the usage and default of the
+		// field that defined it.
+		if existing := c.flags.Lookup(tag.name); existing != nil {
+			if existing.Usage == "" {
+				existing.Usage = tag.usage
+			}
+			if existing.DefValue == "" && tag.value != "" {
+				existing.DefValue = tag.value
+				existing.Value.Set(tag.value)
+			}
+			continue
+		}
+
+		c.flags.Var(value, tag.name, tag.usage)
+	}
+
+	return nil
+}
+
+// injectFlags fills the fields of t tagged with FlagTagKey in the struct value
+// points to with the values of their flags. Fields whose flag was neither set
+// nor given a default keep their value.
+func (c *Container) injectFlags(value reflect.Value, t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, f := range reflect.VisibleFields(t) {
+		tagValue, ok := f.Tag.Lookup(FlagTagKey)
+		if !ok {
+			continue
+		}
+		name := fieldPath(t, f.Index)
+		tag, err := parseFlagTag(tagValue)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", name, err)
+		}
+
+		var fl *flag.Flag
+		if c.flags != nil {
+			fl = c.flags.Lookup(tag.name)
+		}
+		if fl == nil {
+			return fmt.Errorf("field '%s': flag '%s' is not defined, call Validate before parsing the flags", name, tag.name)
+		}
+
+		raw := fl.Value.String()
+		if raw == "" {
+			continue
+		}
+		val, err := convertConfig(raw, f.Type)
+		if err != nil {
+			return fmt.Errorf("field '%s': flag '%s': %w", name, tag.name, err)
+		}
+		settableField(value, f.Index).Set(val)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package di
+
+import "reflect"
+
+// resolveGraph holds the instances of PerResolve items created while one
+// top-level resolution builds its object graph, and the items being created.
+type resolveGraph struct {
+	instances map[*ItemDescriptor]any
+	owners    []*ItemDescriptor
+	done      []func()
+}
+
+// owner returns the item whose dependencies are being resolved, or nil for a
+// top-level resolution.
+func (g *resolveGraph) owner() *ItemDescriptor {
+	if g == nil || len(g.owners) == 0 {
+		return nil
+	}
+	return g.owners[len(g.owners)-1]
+}
+
+// chain returns the items whose dependencies are being resolved, from the
+// top-level item to the current owner.
+func (g *resolveGraph) chain() []*ItemDescriptor {
+	if g == nil {
+		return nil
+	}
+	return g.owners
+}
+
+// building records d as the item whose dependencies are being resolved, until
+// the returned function is called.
+func (c *Container) building(d *ItemDescriptor) func() {
+	g := c.graph
+	if g == nil {
+		return func() {}
+	}
+	g.owners = append(g.owners, d)
+	return func() { g.owners = g.owners[:len(g.owners)-1] }
+}
+
+// withGraph returns c if it is already building an object graph, or a copy of
+// c that starts a new one. Copies share the state of c. The returned function
+// must be called when the resolution is complete.
+func (c *Container) withGraph() (*Container, func()) {
+	if c.graph != nil {
+		return c, func() {}
+	}
+	g := *c
+	g.origin = c
+	g.graph = &resolveGraph{instances: make(map[*ItemDescriptor]any)}
+	return &g, func() {
+		for _, done := range g.graph.done {
+			done()
+		}
+	}
+}
+
+// withoutGraph returns c, or the container c was copied from to build an
+// object graph, for values that resolve items after the graph is built.
+func (c *Container) withoutGraph() *Container {
+	if c.origin == nil {
+		return c
+	}
+	return c.origin
+}
+
+// perResolveLifetime shares an instance within the object graph being built.
+// Resolutions outside of a graph get a new instance.
+type perResolveLifetime struct{}
+
+func (perResolveLifetime) Resolve(r Resolution, create func() (any, error)) (any, error) {
+	g := r.c.graph
+	if g != nil {
+		if ins, ok := g.instances[r.item]; ok {
+			return ins, nil
+		}
+	}
+
+	ins, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if ins != nil {
+		r.c.trackResolved(reflect.ValueOf(ins))
+	}
+	if g != nil {
+		g.instances[r.item] = ins
+	}
+	return ins, nil
+}
+
+func (perResolveLifetime) Dispose(*Container) error {
+	return nil
+}
+
+func (perResolveLifetime) EndsWithScope() bool {
+	return true
+}
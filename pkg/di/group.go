@@ -0,0 +1,129 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// resolveField resolves the value of an injection field, following the lazy
+// and group options of its tag.
+func (c *Container) resolveField(f injectFieldInfo) (reflect.Value, error) {
+	name := itemNameOf(f.itemName)
+	switch {
+	case f.group:
+		return c.resolveGroup(f.fieldType)
+	case f.lazy:
+		return c.lazyFunc(f.fieldType, name, f.optional)
+	}
+	return c.resolveInjection(f.fieldType, name, f.optional)
+}
+
+// checkLazyType reports whether t can hold a lazy injection, a Lazy[T] or a
+// func() T or func() (T, error).
+func checkLazyType(t reflect.Type) error {
+	if isBinder(t) {
+		return nil
+	}
+	if t.Kind() != reflect.Func || t.NumIn() != 0 || t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) {
+		return fmt.Errorf("lazy field of type '%s' must be a Lazy[T], a func() T or a func() (T, error)", t)
+	}
+	return nil
+}
+
+// lazyFunc returns a function of type t which resolves the item on its first
+// call and returns the same result on every following call. A func() T panics
+// if the item cannot be resolved.
+func (c *Container) lazyFunc(t reflect.Type, name string, optional bool) (reflect.Value, error) {
+	if err := checkLazyType(t); err != nil {
+		return reflect.Value{}, err
+	}
+	if isBinder(t) {
+		return c.resolveInjection(t, name, optional)
+	}
+
+	c = c.lateContainer()
+	var (
+		once  sync.Once
+		value reflect.Value
+		err   error
+	)
+	return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		once.Do(func() {
+			g, done := c.withGraph()
+			defer done()
+			value, err = g.resolveInjection(t.Out(0), name, optional)
+		})
+		if t.NumOut() == 1 {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{value}
+		}
+		if err != nil {
+			return []reflect.Value{reflect.Zero(t.Out(0)), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{value, reflect.Zero(errorType)}
+	}), nil
+}
+
+// groupItems returns the items assignable to elem, registered by type in the
+// order of their type names, then by name in the order of their names. Values
+// provided to a scope are not part of a group.
+func (c *Container) groupItems(elem reflect.Type) []*ItemDescriptor {
+	matches := func(des *ItemDescriptor) bool {
+		return !des.provided && (des.itemType.AssignableTo(elem) || reflect.PointerTo(des.itemType).AssignableTo(elem))
+	}
+
+	var types []reflect.Type
+	for t, des := range c.typeItems {
+		if matches(des) {
+			types = append(types, t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	var names []string
+	for name, des := range c.namedItems {
+		if matches(des) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	items := make([]*ItemDescriptor, 0, len(types)+len(names))
+	for _, t := range types {
+		items = append(items, c.typeItems[t])
+	}
+	for _, name := range names {
+		items = append(items, c.namedItems[name])
+	}
+	return items
+}
+
+// resolveGroup resolves every item assignable to the element type of the
+// slice type t into a slice.
+func (c *Container) resolveGroup(t reflect.Type) (reflect.Value, error) {
+	if t.Kind() != reflect.Slice {
+		return reflect.Value{}, fmt.Errorf("group field of type '%s' must be a slice", t)
+	}
+
+	items := c.groupItems(t.Elem())
+	slice := reflect.MakeSlice(t, 0, len(items))
+	for _, des := range items {
+		ptr, err := c.resolveItemValue(des)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if ptr == nil {
+			continue
+		}
+		val, ok := assignableValue(*ptr, t.Elem())
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("type '%s' not match to item type '%s'", t.Elem(), des.itemType)
+		}
+		slice = reflect.Append(slice, val)
+	}
+	return slice, nil
+}
@@ -0,0 +1,23 @@
+package di
+
+import "net/http"
+
+// Handler returns an http.Handler that, on every request, resolves THandler
+// from the request scope created by HTTPMiddleware and calls its ServeHTTP.
+// Resolution failures are passed to the error handler set by WithErrorHandler.
+func Handler[THandler any, PHandler interface {
+	*THandler
+	http.Handler
+}](opts ...HTTPOption) http.Handler {
+	o := newHTTPOptions(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, err := ResolveFromContext[THandler](r.Context())
+		if err != nil {
+			o.errorHandler(w, r, err)
+			return
+		}
+
+		PHandler(h).ServeHTTP(w, r)
+	})
+}
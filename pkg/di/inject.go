@@ -0,0 +1,105 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// lookupItem finds the item to inject into a field or parameter of type t. A
+// named item is looked up by name. Otherwise pointer types are looked up by
+// the type they point to and any other type by itself. An interface type that
+// is not registered falls back to the only item registered in the master
+// container implementing it, values provided to a scope are not considered.
+// A nil descriptor without error means that nothing is registered.
+func (c *Container) lookupItem(t reflect.Type, name string) (*ItemDescriptor, error) {
+	if name != "" {
+		return c.namedItems[name], nil
+	}
+
+	itemType := t
+	if t.Kind() == reflect.Pointer {
+		itemType = t.Elem()
+	}
+	if des := c.typeItems[itemType]; des != nil {
+		return des, nil
+	}
+
+	if t.Kind() != reflect.Interface {
+		return nil, nil
+	}
+
+	var found *ItemDescriptor
+	for it, des := range c.typeItems {
+		if des.provided {
+			continue
+		}
+		if !it.AssignableTo(t) && !reflect.PointerTo(it).AssignableTo(t) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("type '%s' is implemented by more than one registered item", t)
+		}
+		found = des
+	}
+	return found, nil
+}
+
+// notRegisteredError returns the error reported when nothing is registered
+// for a field or parameter of type t.
+func notRegisteredError(t reflect.Type, name string) error {
+	if name != "" {
+		return fmt.Errorf("no any instance register by name '%s'", name)
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return fmt.Errorf("type '%s' not registered", t)
+}
+
+// assignableValue returns the instance ptr of an item, or the value it points
+// to, whichever is assignable to t. Pointers are injected as they are, so
+// pointer and interface fields share the instance, while any other type gets a
+// copy of the value.
+func assignableValue(ptr reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if ptr.Type().AssignableTo(t) {
+		return ptr, true
+	}
+	if ptr.Elem().Type().AssignableTo(t) {
+		return ptr.Elem(), true
+	}
+	return reflect.Value{}, false
+}
+
+// resolveInjection resolves a value assignable to a field or parameter of
+// type t, by name if name is not empty. If optional is set and nothing is
+// registered, the zero value of t is returned.
+func (c *Container) resolveInjection(t reflect.Type, name string, optional bool) (reflect.Value, error) {
+	if isBinder(t) {
+		return c.newBinder(t, name)
+	}
+
+	des, err := c.lookupItem(t, name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if des == nil {
+		if optional {
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, notRegisteredError(t, name)
+	}
+
+	ptr, err := c.resolveItemValue(des)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if ptr == nil {
+		return reflect.Zero(t), nil
+	}
+
+	val, ok := assignableValue(*ptr, t)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("type '%s' not match to item type '%s'", t, des.itemType)
+	}
+	return val, nil
+}
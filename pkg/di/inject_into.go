@@ -0,0 +1,67 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// InjectInto sets the tagged fields of an object the container did not
+// create, such as one built by a framework or by test code, and calls its
+// Inject method if it has one. ptr must be a non-nil pointer.
+func (c *Container) InjectInto(ptr any) error {
+	value := reflect.ValueOf(ptr)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("cannot inject into '%T', value is not a non-nil pointer", ptr)
+	}
+	if c.life.isDisposed() {
+		return errors.New("cannot inject from disposed container")
+	}
+
+	g, done := c.withGraph()
+	defer done()
+	return g.inject(value, value.Type().Elem(), nil)
+}
+
+// InjectInto sets the tagged fields of obj from the container c.
+func InjectInto[T any](c *Container, obj *T) error {
+	return c.InjectInto(obj)
+}
+
+// injection guards the injection of a registered instance, which can be
+// resolved from several scopes at once.
+type injection struct {
+	mu       sync.Mutex
+	injected bool
+}
+
+// injectRegistered injects the fields of a registered instance once. Other
+// goroutines wait until the injection is complete, while the object graph
+// injecting the instance can resolve it from its own fields.
+func (c *Container) injectRegistered(d *ItemDescriptor) error {
+	if d.injection == nil || d.instance == nil {
+		return nil
+	}
+
+	c, done := c.withGraph()
+	defer done()
+	for _, owner := range c.graph.owners {
+		if owner == d {
+			return nil
+		}
+	}
+
+	d.injection.mu.Lock()
+	defer d.injection.mu.Unlock()
+	if d.injection.injected {
+		return nil
+	}
+
+	defer c.building(d)()
+	if err := c.inject(*d.instance, d.itemType, d.injectMethods); err != nil {
+		return err
+	}
+	d.injection.injected = true
+	return nil
+}
@@ -0,0 +1,127 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// InvokeOption configures Invoke.
+type InvokeOption func(*invokeOptions)
+
+type invokeOptions struct {
+	optional map[int]bool
+}
+
+// OptionalParams marks the parameters at the given indexes as optional. An
+// optional parameter whose type is not registered receives its zero value
+// instead of failing the call.
+func OptionalParams(indexes ...int) InvokeOption {
+	return func(o *invokeOptions) {
+		for _, i := range indexes {
+			o.optional[i] = true
+		}
+	}
+}
+
+func newInvokeOptions(opts []InvokeOption) *invokeOptions {
+	o := &invokeOptions{optional: make(map[int]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// checkFunc validates that t is a function that can be invoked by the
+// container: it must not be variadic and may only return an error.
+func checkFunc(t reflect.Type) error {
+	if t == nil || t.Kind() != reflect.Func {
+		return fmt.Errorf("cannot invoke '%v', value is not a function", t)
+	}
+	if t.IsVariadic() {
+		return fmt.Errorf("cannot invoke variadic function '%s'", t)
+	}
+	if t.NumOut() > 1 || (t.NumOut() == 1 && t.Out(0) != errorType) {
+		return fmt.Errorf("cannot invoke function '%s', it may only return an error", t)
+	}
+	return nil
+}
+
+// call resolves the parameters of fn starting at index from, appends them to
+// args and calls fn.
+func (c *Container) call(fn reflect.Value, args []reflect.Value, optional map[int]bool) error {
+	ft := fn.Type()
+	for i := len(args); i < ft.NumIn(); i++ {
+		val, err := c.resolveInjection(ft.In(i), "", optional[i])
+		if err != nil {
+			return fmt.Errorf("cannot resolve parameter %d of '%s': %w", i, ft, err)
+		}
+		args = append(args, val)
+	}
+
+	results := fn.Call(args)
+	if len(results) == 1 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
+
+// Invoke resolves every parameter of fn from the container and calls it. fn
+// may return an error, which is returned by Invoke.
+func Invoke(c *Container, fn any, opts ...InvokeOption) error {
+	o := newInvokeOptions(opts)
+
+	fv := reflect.ValueOf(fn)
+	if err := checkFunc(reflect.TypeOf(fn)); err != nil {
+		return err
+	}
+	if fv.IsNil() {
+		return errors.New("cannot invoke nil function")
+	}
+
+	g, done := c.withGraph()
+	defer done()
+	return g.call(fv, nil, o.optional)
+}
+
+// HandlerFunc adapts fn to an http.Handler. fn must take an
+// http.ResponseWriter and an *http.Request followed by any number of
+// parameters, which are resolved from the request scope created by
+// HTTPMiddleware. Parameters can be marked optional with WithInvokeOptions.
+// fn may return an error. Resolution failures and returned errors are passed
+// to the error handler set by WithErrorHandler. The error handler is called
+// even if fn already wrote to the response, so fn should not return an error
+// after writing it.
+//
+// HandlerFunc panics if fn does not have a supported signature.
+func HandlerFunc(fn any, opts ...HTTPOption) http.Handler {
+	ft := reflect.TypeOf(fn)
+	if err := checkFunc(ft); err != nil {
+		panic(err)
+	}
+	if ft.NumIn() < 2 || ft.In(0) != responseWriterType || ft.In(1) != reflect.PointerTo(requestType) {
+		panic(fmt.Errorf("handler function '%s' must start with http.ResponseWriter and *http.Request parameters", ft))
+	}
+
+	fv := reflect.ValueOf(fn)
+	o := newHTTPOptions(opts)
+	io := newInvokeOptions(o.invoke)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := FromContext(r.Context())
+		if !ok {
+			o.errorHandler(w, r, errNoContainer)
+			return
+		}
+
+		args := []reflect.Value{reflect.ValueOf(&w).Elem(), reflect.ValueOf(r)}
+		g, done := c.withGraph()
+		defer done()
+		if err := g.call(fv, args, io.optional); err != nil {
+			o.errorHandler(w, r, err)
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package di
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+	"time"
+)
 
 type ItemFactory func(Container) any
 
@@ -10,6 +14,42 @@ type ItemDescriptor struct {
 	lifetime Lifetime
 	instance *reflect.Value
 	factory  ItemFactory
+	provided bool
+
+	injectOnResolve bool
+	injection       *injection
+	injectMethods   []string
+
+	construct func(c *Container) (*reflect.Value, error)
+
+	// pool holds the released instances of a Pooled item. It is shared by the
+	// scoped copies of the descriptor.
+	pool *sync.Pool
+
+	ttl    time.Duration
+	expiry *expiry
+}
+
+// scopedCopy returns a copy of the descriptor without its instance, used by a
+// scope to hold its own instance of the item. The copy shares the pool of the
+// item and has its own expiry.
+func (des *ItemDescriptor) scopedCopy() *ItemDescriptor {
+	copied := &ItemDescriptor{
+		name:            des.name,
+		itemType:        des.itemType,
+		lifetime:        des.lifetime,
+		instance:        nil,
+		factory:         des.factory,
+		injectOnResolve: des.injectOnResolve,
+		injectMethods:   des.injectMethods,
+		construct:       des.construct,
+		pool:            des.pool,
+		ttl:             des.ttl,
+	}
+	if des.expiry != nil {
+		copied.expiry = &expiry{}
+	}
+	return copied
 }
 
 func (des *ItemDescriptor) Name() *string {
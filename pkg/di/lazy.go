@@ -0,0 +1,157 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// binder is implemented by wrapper types that are injected bound to a
+// container, instead of an instance resolved at injection time.
+type binder interface {
+	bind(c *Container, name string) error
+}
+
+var binderType = reflect.TypeOf((*binder)(nil)).Elem()
+
+// deferredBinder is implemented by binders that resolve their item after the
+// owning object is built, such as Lazy and Provider.
+type deferredBinder interface {
+	binder
+	deferred()
+}
+
+var errNotBound = errors.New("item is not bound to a container")
+
+// isBinder reports whether values of t are bound to a container on injection.
+func isBinder(t reflect.Type) bool {
+	return t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(binderType)
+}
+
+// newBinder creates a value of type t bound to the container. Binders that
+// resolve on injection, such as Optional, share the object graph being built,
+// while deferred binders are bound to the container returned by
+// lateContainer.
+func (c *Container) newBinder(t reflect.Type, name string) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	b := ptr.Interface().(binder)
+	bc := c
+	if _, ok := b.(deferredBinder); ok {
+		bc = c.lateContainer()
+	}
+	if err := b.bind(bc, name); err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
+
+// lateContainer returns the container that items are resolved from after the
+// object graph is built. Objects shared with scopes, and the objects built for
+// them, outlive the scope that resolves them, so they are bound to the master
+// container instead.
+func (c *Container) lateContainer() *Container {
+	late := c.withoutGraph()
+	if c.graph != nil {
+		for _, d := range c.graph.owners {
+			if owner := late.owner(d); owner != late {
+				return owner
+			}
+		}
+	}
+	return late
+}
+
+// resolveItem resolves an item of type TItem by name, or by type if name is
+// empty.
+func resolveItem[TItem any](c *Container, name string) (*TItem, error) {
+	if name != "" {
+		return ResolveByName[TItem](c, name)
+	}
+	return Resolve[TItem](c)
+}
+
+// Lazy defers the resolution of an item until Get is first called. A field of
+// type Lazy[T] tagged with di.inject is bound to the container that created
+// the owning object, so it can be used to break dependency cycles. Objects
+// shared with scopes, such as singletons, are bound to the master container.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	once  sync.Once
+	c     *Container
+	name  string
+	value *T
+	err   error
+}
+
+func (l *Lazy[T]) bind(c *Container, name string) error {
+	l.state = &lazyState[T]{c: c, name: name}
+	return nil
+}
+
+func (l *Lazy[T]) deferred() {}
+
+// Get resolves the item on first use and returns the same result on every
+// following call.
+func (l *Lazy[T]) Get() (*T, error) {
+	s := l.state
+	if s == nil {
+		return nil, errNotBound
+	}
+	s.once.Do(func() {
+		s.value, s.err = resolveItem[T](s.c, s.name)
+	})
+	return s.value, s.err
+}
+
+// Provider resolves an item every time Get is called, following the lifetime
+// the item was registered with. A field of type Provider[T] tagged with
+// di.inject is bound to the container that created the owning object, or the
+// master container for objects shared with scopes.
+type Provider[T any] struct {
+	c    *Container
+	name string
+}
+
+func (p *Provider[T]) bind(c *Container, name string) error {
+	p.c = c
+	p.name = name
+	return nil
+}
+
+func (p *Provider[T]) deferred() {}
+
+// Get resolves the item from the container the provider is bound to.
+func (p *Provider[T]) Get() (*T, error) {
+	if p.c == nil {
+		return nil, errNotBound
+	}
+	return resolveItem[T](p.c, p.name)
+}
+
+// Optional holds an item that may not be registered. A field of type
+// Optional[T] tagged with di.inject is resolved on injection, within the
+// resolution of the owning object, and left empty when nothing is registered,
+// instead of failing the resolution.
+type Optional[T any] struct {
+	value *T
+}
+
+func (o *Optional[T]) bind(c *Container, name string) error {
+	if !c.isRegistered(reflect.TypeOf(new(T)).Elem(), name) {
+		return nil
+	}
+	value, err := resolveItem[T](c, name)
+	if err != nil {
+		return err
+	}
+	o.value = value
+	return nil
+}
+
+// Get returns the item and whether it was registered.
+func (o *Optional[T]) Get() (*T, bool) {
+	return o.value, o.value != nil
+}
@@ -1,5 +1,11 @@
 package di
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
 type Lifetime string
 
 const (
@@ -9,4 +15,244 @@ const (
 	Scoped Lifetime = "scoped"
 	// Specifies that a new instance of the service will be created every time it is requested.
 	Transient Lifetime = "transient"
+	// Specifies that instances of the service are taken from a pool when requested
+	// and returned to it when the scope that resolved them is disposed.
+	Pooled Lifetime = "pooled"
+	// Specifies that a single instance of the service is shared by the objects built
+	// within one top-level resolution, such as a call to Resolve or Invoke.
+	PerResolve Lifetime = "per-resolve"
 )
+
+// LifetimeManager decides which instance of an item a container hands out.
+// The built-in lifetimes are implemented with it, and RegisterLifetime adds
+// custom ones, such as per-tenant or per-connection lifetimes. A manager can
+// also implement SharedLifetime, ScopeBoundLifetime and LifetimeSetup.
+type LifetimeManager interface {
+	// Resolve returns the instance of the item described by r. create builds
+	// a new instance with its fields injected, it returns nil if the factory
+	// of the item does.
+	Resolve(r Resolution, create func() (any, error)) (any, error)
+	// Dispose is called when scope is disposed, after the instances it created
+	// have been disposed, to release the instances held for it. scope is the
+	// container returned by Resolution.Scope.
+	Dispose(scope *Container) error
+}
+
+// SharedLifetime may be implemented by a LifetimeManager whose items are
+// shared by the master container and all of its scopes when SharedWithScopes
+// returns true. Such items keep the descriptor of the master container in
+// every scope and the instances the container tracks for them, those of
+// Singleton items, are disposed with the master container. Items of other
+// lifetimes get a copy of their descriptor in every scope, owned by the scope.
+type SharedLifetime interface {
+	SharedWithScopes() bool
+}
+
+// ScopeBoundLifetime may be implemented by a LifetimeManager whose instances
+// live no longer than the scope that resolves them when EndsWithScope returns
+// true. Only items of such lifetimes may depend on Pooled items.
+type ScopeBoundLifetime interface {
+	EndsWithScope() bool
+}
+
+// LifetimeSetup may be implemented by a LifetimeManager to prepare the
+// descriptor of an item when it is registered, such as the pool of Pooled
+// items. Only items registered after the manager are set up.
+type LifetimeSetup interface {
+	Setup(item *ItemDescriptor)
+}
+
+// Resolution describes the resolution of an item, given to a LifetimeManager.
+type Resolution struct {
+	// c carries the object graph of the top-level resolution.
+	c    *Container
+	item *ItemDescriptor
+}
+
+// Scope returns the container the item is resolved from, the master
+// container or one of its scopes.
+func (r Resolution) Scope() *Container {
+	return r.c.withoutGraph()
+}
+
+// Item returns the descriptor of the item being resolved.
+func (r Resolution) Item() *ItemDescriptor {
+	return r.item
+}
+
+// Owner returns the item the resolved item is injected into, or nil if it is
+// resolved directly.
+func (r Resolution) Owner() *ItemDescriptor {
+	return r.c.graph.owner()
+}
+
+// lifetimes holds the lifetime managers of a container and its scopes, in
+// order of registration.
+type lifetimes struct {
+	managers map[Lifetime]LifetimeManager
+	order    []Lifetime
+}
+
+func newLifetimes() *lifetimes {
+	l := &lifetimes{managers: make(map[Lifetime]LifetimeManager)}
+	l.add(Singleton, singletonLifetime{})
+	l.add(Scoped, scopedLifetime{})
+	l.add(Transient, transientLifetime{})
+	l.add(Pooled, pooledLifetime{})
+	l.add(PerResolve, perResolveLifetime{})
+	return l
+}
+
+func (l *lifetimes) add(lifetime Lifetime, manager LifetimeManager) {
+	l.managers[lifetime] = manager
+	l.order = append(l.order, lifetime)
+}
+
+// shared reports whether items of lifetime are shared with scopes.
+func (l *lifetimes) shared(lifetime Lifetime) bool {
+	s, ok := l.managers[lifetime].(SharedLifetime)
+	return ok && s.SharedWithScopes()
+}
+
+// endsWithScope reports whether instances of lifetime live no longer than the
+// scope that resolves them.
+func (l *lifetimes) endsWithScope(lifetime Lifetime) bool {
+	s, ok := l.managers[lifetime].(ScopeBoundLifetime)
+	return ok && s.EndsWithScope()
+}
+
+func (l *lifetimes) manager(lifetime Lifetime) (LifetimeManager, error) {
+	manager := l.managers[lifetime]
+	if manager == nil {
+		return nil, fmt.Errorf("lifetime '%s' is not registered", lifetime)
+	}
+	return manager, nil
+}
+
+// dispose calls the Dispose method of every manager for scope, in reverse
+// order of registration.
+func (l *lifetimes) dispose(scope *Container) []error {
+	var errs []error
+	for i := len(l.order) - 1; i >= 0; i-- {
+		if err := l.managers[l.order[i]].Dispose(scope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// RegisterLifetime registers the manager of a custom lifetime, which items can
+// then be registered with.
+func (c *Container) RegisterLifetime(lifetime Lifetime, manager LifetimeManager, safe bool) error {
+	if c.scoped {
+		err := errScopeRegistration
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
+	if manager == nil {
+		err := errors.New("lifetime manager could not be null")
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
+	if c.lifetimes.managers[lifetime] != nil {
+		err := fmt.Errorf("lifetime '%s' is already registered", lifetime)
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
+	c.lifetimes.add(lifetime, manager)
+	return nil
+}
+
+// singletonLifetime keeps the instance on the descriptor of the master
+// container, rebuilding it when it expires.
+type singletonLifetime struct{}
+
+func (singletonLifetime) Resolve(r Resolution, create func() (any, error)) (any, error) {
+	item := r.item
+	if item.name != nil { //registered instant by name
+		return item.instance.Interface(), nil
+	}
+
+	if item.expiry != nil {
+		return r.c.resolveExpiring(item, create)
+	}
+	if item.instance == nil {
+		ins, err := create()
+		if ins == nil || err != nil {
+			return nil, err
+		}
+		value := reflect.ValueOf(ins)
+		item.instance = &value
+		r.c.owner(item).life.track(value)
+	}
+	return item.instance.Interface(), nil
+}
+
+func (singletonLifetime) Dispose(*Container) error {
+	return nil
+}
+
+func (singletonLifetime) SharedWithScopes() bool {
+	return true
+}
+
+// Setup prepares the expiry of items registered with ExpiresAfter.
+func (singletonLifetime) Setup(item *ItemDescriptor) {
+	item.expiry = newExpiry(item.ttl)
+}
+
+// scopedLifetime keeps the instance on the descriptor of the scope, which
+// scopes copy from the master container.
+type scopedLifetime struct{}
+
+func (scopedLifetime) Resolve(r Resolution, create func() (any, error)) (any, error) {
+	if !r.c.scoped {
+		return nil, errors.New("cannot resolve scoped item with none scoped container")
+	}
+	return singletonLifetime{}.Resolve(r, create)
+}
+
+func (scopedLifetime) Dispose(*Container) error {
+	return nil
+}
+
+func (scopedLifetime) EndsWithScope() bool {
+	return true
+}
+
+// Setup prepares the expiry of items registered with ExpiresAfter.
+func (scopedLifetime) Setup(item *ItemDescriptor) {
+	item.expiry = newExpiry(item.ttl)
+}
+
+// transientLifetime creates an instance on every resolution.
+type transientLifetime struct{}
+
+func (transientLifetime) Resolve(r Resolution, create func() (any, error)) (any, error) {
+	ins, err := create()
+	if ins == nil || err != nil {
+		return nil, err
+	}
+	r.c.trackResolved(reflect.ValueOf(ins))
+	return ins, nil
+}
+
+func (transientLifetime) Dispose(*Container) error {
+	return nil
+}
+
+func (transientLifetime) EndsWithScope() bool {
+	return true
+}
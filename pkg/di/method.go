@@ -0,0 +1,64 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// InjectMethodName is the name of the method the container calls on every
+// instance it injects, if the instance has one. Its parameters are resolved
+// like those of Invoke and it may return an error.
+const InjectMethodName = "Inject"
+
+// inject sets the tagged fields of the object value points to, fills its
+// config, flag and secret fields and then calls its injection methods: the
+// Inject method if it has one, followed by the given methods.
+func (c *Container) inject(value reflect.Value, t reflect.Type, methods []string) error {
+	if err := c.injectFields(value, t); err != nil {
+		return err
+	}
+	if err := c.injectConfig(value, t); err != nil {
+		return err
+	}
+	if err := c.injectFlags(value, t); err != nil {
+		return err
+	}
+	if err := c.injectSecrets(value, t); err != nil {
+		return err
+	}
+
+	if value.Kind() != reflect.Pointer {
+		return nil
+	}
+
+	if m := value.MethodByName(InjectMethodName); m.IsValid() {
+		if err := c.callInjectMethod(m, t, InjectMethodName); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range methods {
+		if name == InjectMethodName {
+			continue
+		}
+		m := value.MethodByName(name)
+		if !m.IsValid() {
+			return fmt.Errorf("method '%s' not found on type '%s'", name, t)
+		}
+		if err := c.callInjectMethod(m, t, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Container) callInjectMethod(m reflect.Value, t reflect.Type, name string) error {
+	if err := checkFunc(m.Type()); err != nil {
+		return fmt.Errorf("method '%s' of type '%s': %w", name, t, err)
+	}
+	if err := c.call(m, nil, nil); err != nil {
+		return fmt.Errorf("method '%s' of type '%s': %w", name, t, err)
+	}
+	return nil
+}
@@ -2,19 +2,166 @@ package di
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"reflect"
 )
 
+// contextKey is the key under which the request scope is stored in a context.
+type contextKey struct{}
+
+// ContextKey is the type of ContextContainerKey.
+//
+// Deprecated: Use NewContext and FromContext.
 type ContextKey string
 
+// ContextContainerKey is the key under which contexts created by NewContext
+// also carry the container.
+//
+// Deprecated: Use FromContext.
 const ContextContainerKey ContextKey = "container"
 
+var errNoContainer = errors.New("no container found in context")
+
+// ErrorHandler writes the response for a request that could not be served
+// because of a container error.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// HTTPOption configures the HTTP helpers of the container.
+type HTTPOption func(*httpOptions)
+
+type httpOptions struct {
+	errorHandler ErrorHandler
+	scopeValues  []func(r *http.Request) ([]any, error)
+	invoke       []InvokeOption
+}
+
+func newHTTPOptions(opts []HTTPOption) *httpOptions {
+	o := &httpOptions{errorHandler: defaultErrorHandler}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func defaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// WithErrorHandler sets the handler used to respond when the container fails
+// to serve a request. By default a 500 Internal Server Error is written.
+func WithErrorHandler(h ErrorHandler) HTTPOption {
+	return func(o *httpOptions) {
+		if h != nil {
+			o.errorHandler = h
+		}
+	}
+}
+
+// WithScopeValues adds a hook that supplies request specific values, such as
+// the authenticated user or a correlation ID. Every returned value is
+// registered in the request scope under its type, pointers under the type
+// they point to, and can be injected into scoped items.
+func WithScopeValues(seed func(r *http.Request) ([]any, error)) HTTPOption {
+	return func(o *httpOptions) {
+		if seed != nil {
+			o.scopeValues = append(o.scopeValues, seed)
+		}
+	}
+}
+
+// WithInvokeOptions sets the options used by HandlerFunc to resolve the
+// parameters of its function, such as OptionalParams. Parameter indexes
+// count the http.ResponseWriter and *http.Request parameters.
+func WithInvokeOptions(opts ...InvokeOption) HTTPOption {
+	return func(o *httpOptions) {
+		o.invoke = append(o.invoke, opts...)
+	}
+}
+
+var (
+	requestType        = reflect.TypeOf(http.Request{})
+	responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+)
+
+// HTTPMiddleware returns a middleware that creates a scope for every request.
+// The scope is bound to the request context, stored in it for FromContext and
+// disposed after the next handler returns, even if it panics.
+//
+// The request, the response writer and the request context are registered in
+// the scope, so *http.Request, http.ResponseWriter and context.Context can be
+// injected into scoped items.
+func HTTPMiddleware(c *Container, opts ...HTTPOption) func(http.Handler) http.Handler {
+	o := newHTTPOptions(opts)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestContext := r.Context()
+			scopedContainer, err := c.NewScopeContext(requestContext)
+			if err != nil {
+				o.errorHandler(w, r, err)
+				return
+			}
+			defer scopedContainer.Dispose()
+
+			requestContext = NewContext(requestContext, scopedContainer)
+			r = r.WithContext(requestContext)
+
+			scopedContainer.provide(contextType, requestContext)
+			scopedContainer.provide(requestType, r)
+			scopedContainer.provide(responseWriterType, w)
+
+			for _, seed := range o.scopeValues {
+				values, err := seed(r)
+				if err != nil {
+					o.errorHandler(w, r, err)
+					return
+				}
+				for _, value := range values {
+					if value == nil {
+						continue
+					}
+					t := reflect.TypeOf(value)
+					if t.Kind() == reflect.Pointer {
+						t = t.Elem()
+					}
+					scopedContainer.provide(t, value)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Middleware wraps next with HTTPMiddleware using the default options.
+//
+// Deprecated: Use HTTPMiddleware, which fits standard middleware chains.
 func Middleware(c *Container, next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestContext := r.Context()
-		scopedContainer, _ := c.NewScope()
-		requestContext = context.WithValue(requestContext, ContextContainerKey, scopedContainer)
+	return HTTPMiddleware(c)(next)
+}
+
+// NewContext returns a copy of ctx that carries the container c.
+func NewContext(ctx context.Context, c *Container) context.Context {
+	ctx = context.WithValue(ctx, ContextContainerKey, c)
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the container stored in ctx, if any.
+func FromContext(ctx context.Context) (*Container, bool) {
+	c, ok := ctx.Value(contextKey{}).(*Container)
+	if !ok {
+		c, ok = ctx.Value(ContextContainerKey).(*Container)
+	}
+	return c, ok && c != nil
+}
 
-		next.ServeHTTP(w, r.WithContext(requestContext))
-	})
+// ResolveFromContext resolves an item of type TResult from the container
+// stored in ctx.
+func ResolveFromContext[TResult any](ctx context.Context) (*TResult, error) {
+	c, ok := FromContext(ctx)
+	if !ok {
+		return nil, errNoContainer
+	}
+	return Resolve[TResult](c)
 }
@@ -0,0 +1,52 @@
+package di
+
+// ContainerOption configures a container created by NewContainer.
+type ContainerOption func(*Container)
+
+// WithTagKey sets the struct tag key used to mark fields for injection,
+// DefaultTagKey by default. It allows structs tagged for other dependency
+// injection libraries, for example with `inject:""`, to be used as they are.
+func WithTagKey(key string) ContainerOption {
+	return func(c *Container) {
+		if key != "" {
+			c.tagKey = key
+		}
+	}
+}
+
+// RegisterOption configures an item when it is registered.
+type RegisterOption func(*ItemDescriptor)
+
+// InjectOnResolve makes the container inject the tagged fields of a registered
+// instance the first time it is resolved. By default instances registered with
+// RegisterInstance or RegisterByName are stored untouched.
+func InjectOnResolve() RegisterOption {
+	return func(d *ItemDescriptor) {
+		d.injectOnResolve = true
+		d.injection = &injection{}
+	}
+}
+
+// InjectMethods makes the container call the named methods of every instance
+// of the item, in order, with their parameters resolved from the container.
+// They are called after field injection, following the Inject method.
+func InjectMethods(names ...string) RegisterOption {
+	return func(d *ItemDescriptor) {
+		d.injectMethods = append(d.injectMethods, names...)
+	}
+}
+
+// newItemDescriptor applies the registration options to d and lets the
+// manager of its lifetime set it up.
+func (c *Container) newItemDescriptor(d *ItemDescriptor, opts []RegisterOption) *ItemDescriptor {
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.instance != nil {
+		d.ttl = 0
+	}
+	if setup, ok := c.lifetimes.managers[d.lifetime].(LifetimeSetup); ok {
+		setup.Setup(d)
+	}
+	return d
+}
@@ -0,0 +1,101 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Resetter is implemented by Pooled items that clear their state before they
+// are handed out again.
+type Resetter interface {
+	Reset()
+}
+
+// pooledInstance is an instance taken from the pool of a Pooled item, returned
+// to it when the container that resolved it is disposed.
+type pooledInstance struct {
+	pool     *sync.Pool
+	instance reflect.Value
+}
+
+// release records an instance to return to pool when the container is disposed.
+func (l *lifecycle) release(pool *sync.Pool, value reflect.Value) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pooled = append(l.pooled, pooledInstance{pool: pool, instance: value})
+}
+
+// checkPooledOwner returns an error if an instance of the Pooled item could
+// be held by one of owners, the chain of items it is injected into, after it
+// returns to the pool. Only owners whose lifetime manager implements
+// ScopeBoundLifetime may depend on Pooled items.
+func (l *lifetimes) checkPooledOwner(item *ItemDescriptor, owners []*ItemDescriptor) error {
+	for _, owner := range owners {
+		if !l.endsWithScope(owner.lifetime) {
+			return fmt.Errorf("pooled item '%s' cannot be injected into '%s' with lifetime '%s', which outlives the scope", item.itemType, owner.itemType, owner.lifetime)
+		}
+	}
+	return nil
+}
+
+// pooledLifetime takes instances from the pool of the item, resetting them and
+// injecting their fields again, or creates one when the pool is empty.
+type pooledLifetime struct{}
+
+func (pooledLifetime) Resolve(r Resolution, create func() (any, error)) (any, error) {
+	scope, item := r.c, r.item
+	if !scope.scoped {
+		return nil, errors.New("cannot resolve pooled item with none scoped container")
+	}
+	if err := scope.lifetimes.checkPooledOwner(item, scope.graph.chain()); err != nil {
+		return nil, err
+	}
+
+	if value, ok := item.pool.Get().(reflect.Value); ok {
+		if r, ok := value.Interface().(Resetter); ok {
+			r.Reset()
+		}
+		done := scope.building(item)
+		err := scope.inject(value, item.itemType, item.injectMethods)
+		done()
+		if err != nil {
+			item.pool.Put(value)
+			return nil, err
+		}
+		scope.life.release(item.pool, value)
+		return value.Interface(), nil
+	}
+
+	ins, err := create()
+	if ins == nil || err != nil {
+		return nil, err
+	}
+	scope.life.release(item.pool, reflect.ValueOf(ins))
+	return ins, nil
+}
+
+func (pooledLifetime) EndsWithScope() bool {
+	return true
+}
+
+// Setup creates the pool of the item, shared by the copies of its descriptor
+// in every scope.
+func (pooledLifetime) Setup(item *ItemDescriptor) {
+	item.pool = new(sync.Pool)
+}
+
+// Dispose returns the instances resolved by scope to their pool.
+func (pooledLifetime) Dispose(scope *Container) error {
+	l := scope.life
+	l.mu.Lock()
+	pooled := l.pooled
+	l.pooled = nil
+	l.mu.Unlock()
+
+	for _, p := range pooled {
+		p.pool.Put(p.instance)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+	"unsafe"
+)
+
+// CloneMethodName is the name of the method used to copy the template of a
+// prototype. It must take no parameters and return a *T or a T.
+const CloneMethodName = "Clone"
+
+// RegisterPrototype registers a template which is copied every time T is
+// resolved. The copy is made by the Clone method of the template if it has
+// one, or by copying the template value otherwise, and then has its tagged
+// fields injected. Slices and maps are copied with their elements, while
+// pointers, interfaces and channels are shared with the template, implement
+// Clone to copy more. Changes made to the template after registration are
+// seen by the following copies.
+func RegisterPrototype[T any](c *Container, template *T, safe bool, opts ...RegisterOption) error {
+	if template == nil {
+		err := errors.New("cannot register nil prototype")
+		if safe {
+			return err
+		} else {
+			panic(err)
+		}
+	}
+
+	t := reflect.TypeOf(template).Elem()
+	clone := cloneFunc(reflect.ValueOf(template))
+	return c.RegisterFactory(t, Transient, func(Container) any { return clone() }, safe, opts...)
+}
+
+// cloneFunc returns a function copying the value ptr points to.
+func cloneFunc(ptr reflect.Value) func() any {
+	t := ptr.Type()
+	method, ok := t.MethodByName(CloneMethodName)
+	if ok && method.Type.NumIn() == 1 && method.Type.NumOut() == 1 {
+		switch method.Type.Out(0) {
+		case t:
+			return func() any {
+				return ptr.Method(method.Index).Call(nil)[0].Interface()
+			}
+		case t.Elem():
+			return func() any {
+				value := reflect.New(t.Elem())
+				value.Elem().Set(ptr.Method(method.Index).Call(nil)[0])
+				return value.Interface()
+			}
+		}
+	}
+
+	return func() any {
+		value := reflect.New(t.Elem())
+		copyValue(value.Elem(), ptr.Elem())
+		return value.Interface()
+	}
+}
+
+// copyValue sets dst, which must be settable, to a copy of src, including its
+// unexported fields. Slices and maps are copied with their elements, so that
+// copies can change them independently. Pointers, interfaces, channels and
+// functions are shared with the original, such as the clients and loggers a
+// template holds.
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		if !src.CanAddr() {
+			src = addressable(src)
+		}
+		for i := 0; i < src.NumField(); i++ {
+			copyValue(exposed(dst.Field(i)), exposed(src.Field(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		for i := 0; i < src.Len(); i++ {
+			copyValue(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			v := reflect.New(src.Type().Elem()).Elem()
+			copyValue(v, iter.Value())
+			m.SetMapIndex(iter.Key(), v)
+		}
+		dst.Set(m)
+	default:
+		dst.Set(src)
+	}
+}
+
+// exposed returns an addressable field usable even if it is unexported.
+func exposed(field reflect.Value) reflect.Value {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+// addressable returns an addressable copy of value.
+func addressable(value reflect.Value) reflect.Value {
+	ptr := reflect.New(value.Type()).Elem()
+	ptr.Set(value)
+	return ptr
+}
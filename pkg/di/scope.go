@@ -0,0 +1,107 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var errScopeRegistration = errors.New("cannot register items in a scope, use Provide instead")
+
+// provide registers value as an instance of t that belongs to the scope only.
+// Pointers to t are stored as they are, any other value is copied.
+func (c *Container) provide(t reflect.Type, value any) {
+	v := reflect.ValueOf(value)
+	ptr := v
+	if v.Kind() != reflect.Pointer || v.Type().Elem() != t {
+		ptr = reflect.New(t)
+		ptr.Elem().Set(v)
+	}
+	c.typeItems[t] = &ItemDescriptor{itemType: t, lifetime: Scoped, instance: &ptr, provided: true}
+	c.life.track(ptr)
+}
+
+func (c *Container) checkProvide(t reflect.Type, value any) error {
+	if !c.scoped {
+		return errors.New("cannot provide items to none scoped container")
+	}
+	if c.life.isDisposed() {
+		return errors.New("cannot provide items to disposed container")
+	}
+	if value == nil {
+		return errors.New("cannot provide nil")
+	}
+	vt := reflect.TypeOf(value)
+	if !vt.AssignableTo(t) && !(vt.Kind() == reflect.Pointer && vt.Elem() == t) {
+		return fmt.Errorf("value of type '%s' cannot be provided as '%s'", vt, t)
+	}
+	return nil
+}
+
+// Provide registers value as an instance of t in the scope. The value shadows
+// any registration of t in the master container for this scope and the scopes
+// created from it, other scopes never see it. Provided values that implement
+// Disposable or io.Closer are disposed together with the scope.
+//
+// Provide fails if the scope already provides t, use Override to replace it.
+func (c *Container) Provide(t reflect.Type, value any) error {
+	if err := c.checkProvide(t, value); err != nil {
+		return err
+	}
+	if des := c.typeItems[t]; des != nil && des.provided && !c.inherited(t) {
+		return fmt.Errorf("type '%s' is already provided in this scope", t)
+	}
+	c.provide(t, value)
+	return nil
+}
+
+// Override registers value as an instance of t in the scope, replacing any
+// value the scope already provides. It follows the same rules as Provide.
+func (c *Container) Override(t reflect.Type, value any) error {
+	if err := c.checkProvide(t, value); err != nil {
+		return err
+	}
+	c.provide(t, value)
+	return nil
+}
+
+// ProvideByName registers value under name in the scope. The value shadows an
+// item registered by the same name in the master container, following the
+// same rules as Provide.
+func (c *Container) ProvideByName(name string, value any) error {
+	if err := c.checkProvide(reflect.TypeOf(value), value); err != nil {
+		return err
+	}
+	if des := c.namedItems[name]; des != nil && des.provided && (c.parent == nil || c.parent.namedItems[name] != des) {
+		return fmt.Errorf("item name '%s' is already provided in this scope", name)
+	}
+
+	t := reflect.TypeOf(value)
+	ptr := reflect.ValueOf(value)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	} else {
+		ptr = reflect.New(t)
+		ptr.Elem().Set(reflect.ValueOf(value))
+	}
+	c.namedItems[name] = &ItemDescriptor{itemType: t, lifetime: Singleton, name: &name, instance: &ptr, provided: true}
+	c.life.track(ptr)
+	return nil
+}
+
+// inherited reports whether the item registered for t in the scope comes from
+// its parent scope.
+func (c *Container) inherited(t reflect.Type) bool {
+	return c.parent != nil && c.parent.typeItems[t] == c.typeItems[t]
+}
+
+// Provide registers value as an instance of TItem in the scope c.
+func Provide[TItem any](c *Container, value *TItem) error {
+	return c.Provide(reflect.TypeOf(new(TItem)).Elem(), value)
+}
+
+// Override registers value as an instance of TItem in the scope c, replacing
+// any value the scope already provides.
+func Override[TItem any](c *Container, value *TItem) error {
+	return c.Override(reflect.TypeOf(new(TItem)).Elem(), value)
+}
@@ -0,0 +1,185 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// SecretTagKey is the struct tag key used to mark fields filled from the
+// secret providers of the container.
+const SecretTagKey = "di.secret"
+
+const redacted = "[REDACTED]"
+
+// Secret holds a sensitive value. It is redacted whenever it is formatted or
+// marshaled, so printing a struct holding secrets never shows them. Use Reveal
+// to read the value.
+type Secret struct {
+	// value is kept behind a pointer so that fmt, which cannot call the
+	// methods of a Secret held in an unexported field, prints an address.
+	value *string
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret(value string) Secret {
+	return Secret{value: &value}
+}
+
+// Reveal returns the value of the secret.
+func (s Secret) Reveal() string {
+	if s.value == nil {
+		return ""
+	}
+	return *s.value
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return "di.Secret{" + redacted + "}"
+}
+
+// Format redacts the secret for every verb, including %x and %q.
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		f.Write([]byte(s.GoString()))
+		return
+	}
+	f.Write([]byte(redacted))
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+var secretType = reflect.TypeOf(Secret{})
+
+// ErrSecretNotFound is returned by a SecretProvider that does not hold the
+// requested secret.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves secrets by key, such as "db/password".
+type SecretProvider interface {
+	Secret(ctx context.Context, key string) (Secret, error)
+}
+
+// fileSecretProvider reads secrets from files in a directory, such as secrets
+// mounted into a container.
+type fileSecretProvider struct {
+	dir string
+}
+
+// NewFileSecretProvider returns a SecretProvider reading the secret "db/password"
+// from the file db/password in dir. Trailing newlines are removed.
+func NewFileSecretProvider(dir string) SecretProvider {
+	return fileSecretProvider{dir: dir}
+}
+
+func (p fileSecretProvider) Secret(ctx context.Context, key string) (Secret, error) {
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return Secret{}, fmt.Errorf("invalid secret key '%s'", key)
+	}
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return Secret{}, ErrSecretNotFound
+	}
+	if err != nil {
+		return Secret{}, err
+	}
+	return NewSecret(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+// envSecretProvider reads secrets from environment variables.
+type envSecretProvider struct {
+	prefix string
+}
+
+// NewEnvSecretProvider returns a SecretProvider reading the secret
+// "db/password" from DB_PASSWORD, or from PREFIX_DB_PASSWORD if prefix is not
+// empty.
+func NewEnvSecretProvider(prefix string) SecretProvider {
+	return envSecretProvider{prefix: prefix}
+}
+
+func (p envSecretProvider) Secret(ctx context.Context, key string) (Secret, error) {
+	name := strings.ToUpper(strings.NewReplacer("/", "_", ".", "_", "-", "_").Replace(key))
+	if p.prefix != "" {
+		name = strings.ToUpper(p.prefix) + "_" + name
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return Secret{}, ErrSecretNotFound
+	}
+	return NewSecret(value), nil
+}
+
+// WithSecretProviders adds secret providers to the container. A secret is
+// looked up in the providers in order, until one holds it.
+func WithSecretProviders(providers ...SecretProvider) ContainerOption {
+	return func(c *Container) {
+		for _, p := range providers {
+			if p != nil {
+				c.secrets = append(c.secrets, p)
+			}
+		}
+	}
+}
+
+// secret looks up key in the secret providers of the container.
+func (c *Container) secret(key string) (Secret, error) {
+	for _, p := range c.secrets {
+		s, err := p.Secret(c.Context(), key)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue
+		}
+		return s, err
+	}
+	return Secret{}, ErrSecretNotFound
+}
+
+// injectSecrets fills the fields of t tagged with SecretTagKey in the struct
+// value points to, written as `di.secret:"key[,optional]"`. The fields must be
+// of type Secret.
+func (c *Container) injectSecrets(value reflect.Value, t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, f := range reflect.VisibleFields(t) {
+		tagValue, ok := f.Tag.Lookup(SecretTagKey)
+		if !ok {
+			continue
+		}
+		name := fieldPath(t, f.Index)
+		// The secret tag shares the grammar of the config tag.
+		tag, err := parseConfigTag(tagValue)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", name, err)
+		}
+		if tag.key == "" {
+			return fmt.Errorf("field '%s': secret key is empty", name)
+		}
+		if f.Type != secretType {
+			return fmt.Errorf("field '%s': secret fields must be of type '%s'", name, secretType)
+		}
+
+		s, err := c.secret(tag.key)
+		if errors.Is(err, ErrSecretNotFound) && tag.optional {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("field '%s': secret '%s': %w", name, tag.key, err)
+		}
+		settableField(value, f.Index).Set(reflect.ValueOf(s))
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ns-go/di/internal/utils"
+)
+
+// DefaultTagKey is the struct tag key used to mark fields for injection.
+const DefaultTagKey = "di.inject"
+
+// injectTag is the parsed value of an injection struct tag. The grammar is
+//
+//	di.inject:"[name][,option]..."
+//
+// where name is the name the item is registered by, empty to resolve the item
+// by the type of the field, or "-" to skip the field. The options are:
+//
+//	optional  leave the field empty when nothing is registered
+//	lazy      resolve the item when a field of type func() T or
+//	          func() (T, error) is first called
+//	group     fill a slice field with every item assignable to its element
+//	          type, which cannot be combined with a name
+type injectTag struct {
+	name     string
+	skip     bool
+	optional bool
+	lazy     bool
+	group    bool
+}
+
+// parseInjectTag parses the value of an injection struct tag.
+func parseInjectTag(value string) (injectTag, error) {
+	name, opts, _ := strings.Cut(value, ",")
+	tag := injectTag{name: strings.TrimSpace(name)}
+
+	if tag.name == "-" && opts == "" {
+		tag.name = ""
+		tag.skip = true
+		return tag, nil
+	}
+
+	if opts == "" {
+		return tag, nil
+	}
+
+	for _, opt := range strings.Split(opts, ",") {
+		var set *bool
+		switch strings.TrimSpace(opt) {
+		case "optional":
+			set = &tag.optional
+		case "lazy":
+			set = &tag.lazy
+		case "group":
+			set = &tag.group
+		default:
+			return tag, fmt.Errorf("unknown injection option '%s'", opt)
+		}
+		if *set {
+			return tag, fmt.Errorf("duplicate injection option '%s'", opt)
+		}
+		*set = true
+	}
+
+	if tag.group && (tag.name != "" || tag.lazy) {
+		return tag, fmt.Errorf("injection option 'group' cannot be combined with a name or 'lazy'")
+	}
+
+	return tag, nil
+}
+
+// fieldTag returns the injection metadata of a field, taken from the mapping
+// configured for its struct if there is one, or from its struct tag.
+func (c *Container) fieldTag(mapping map[string]injectTag, f reflect.StructField) (injectTag, bool, error) {
+	if tag, ok := mapping[f.Name]; ok {
+		return tag, true, nil
+	}
+
+	value, ok := f.Tag.Lookup(c.tagKey)
+	if !ok {
+		return injectTag{}, false, nil
+	}
+	tag, err := parseInjectTag(value)
+	return tag, true, err
+}
+
+// injectionFields returns the fields of t to inject, marked by the tag key of
+// the container or by a mapping registered with Configure. Types other than
+// structs have no fields to inject.
+func (c *Container) injectionFields(t reflect.Type) []injectFieldInfo {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// Collect the fields of t and of the structs it embeds, keeping only the
+	// fields Go's shadowing rules make visible on t.
+	fields := utils.FilterSlice(reflect.VisibleFields(t), func(f reflect.StructField) bool {
+		visible, ok := t.FieldByName(f.Name)
+		return ok && equalIndex(visible.Index, f.Index)
+	})
+
+	// Filter the struct fields to include only those with injection metadata.
+	mapping := c.mappings[t]
+	fields = utils.FilterSlice(fields, func(f reflect.StructField) bool {
+		tag, ok, err := c.fieldTag(mapping, f)
+		return ok && (err != nil || !tag.skip)
+	})
+
+	// The fields of an embedded struct that is injected itself are left to
+	// the injected instance.
+	fields = utils.FilterSlice(fields, func(f reflect.StructField) bool {
+		for _, embedded := range fields {
+			if embedded.Anonymous && len(embedded.Index) < len(f.Index) && equalIndex(embedded.Index, f.Index[:len(embedded.Index)]) {
+				return false
+			}
+		}
+		return true
+	})
+
+	// Map the filtered fields to injectFieldInfo structs.
+	return utils.MapSlice(fields, func(f reflect.StructField) injectFieldInfo {
+		result := injectFieldInfo{}
+		result.fieldName = fieldPath(t, f.Index)
+		result.fieldIndex = f.Index
+		result.fieldType = f.Type
+		tag, _, err := c.fieldTag(mapping, f)
+		if tag.name != "" {
+			result.itemName = &tag.name
+		}
+		result.optional = tag.optional
+		result.lazy = tag.lazy
+		result.group = tag.group
+		result.tagErr = err
+		return result
+	})
+}
+
+func equalIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldPath returns the dotted path of the field at index in t, naming the
+// embedded structs a promoted field is reached through.
+func fieldPath(t reflect.Type, index []int) string {
+	path := ""
+	for i, x := range index {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		f := t.Field(x)
+		if i > 0 {
+			path += "."
+		}
+		path += f.Name
+		t = f.Type
+	}
+	return path
+}
@@ -0,0 +1,130 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Validate checks the registrations of the container without creating any
+// instance. It reports, all together, every injection field whose item is not
+// registered, every invalid struct tag and every lifetime without a manager.
+// It also defines the flags of the fields tagged with FlagTagKey on the flag
+// set set by WithFlagSet, and binds and validates every config section
+// registered with BindConfig.
+func (c *Container) Validate() error {
+	types := make([]reflect.Type, 0, len(c.typeItems))
+	for t, des := range c.typeItems {
+		if des.provided || des.construct != nil {
+			continue
+		}
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	var errs []error
+	for _, t := range types {
+		if _, err := c.lifetimes.manager(c.typeItems[t].lifetime); err != nil {
+			errs = append(errs, fmt.Errorf("type '%s': %w", t, err))
+		}
+		des := c.typeItems[t]
+		for _, f := range c.injectionFields(t) {
+			err := c.validateField(f)
+			if err == nil {
+				err = c.checkPooledField([]*ItemDescriptor{des}, f, map[*ItemDescriptor]bool{des: true})
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("type '%s' field '%s': %w", t, f.fieldName, err))
+			}
+		}
+		if err := c.defineFlags(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	sections := make([]reflect.Type, 0, len(c.config.sections))
+	for t := range c.config.sections {
+		sections = append(sections, t)
+	}
+	sort.Slice(sections, func(i, j int) bool { return sections[i].String() < sections[j].String() })
+	for _, t := range sections {
+		if _, err := c.config.read(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Container) validateField(f injectFieldInfo) error {
+	if f.tagErr != nil {
+		return f.tagErr
+	}
+	if f.group {
+		if f.fieldType.Kind() != reflect.Slice {
+			return fmt.Errorf("group field of type '%s' must be a slice", f.fieldType)
+		}
+		return nil
+	}
+	t := f.fieldType
+	if f.lazy {
+		if err := checkLazyType(t); err != nil {
+			return err
+		}
+		if !isBinder(t) {
+			t = t.Out(0)
+		}
+	}
+	if isBinder(t) {
+		return nil
+	}
+
+	name := itemNameOf(f.itemName)
+	des, err := c.lookupItem(t, name)
+	if err != nil {
+		return err
+	}
+	if des == nil {
+		if f.optional {
+			return nil
+		}
+		return notRegisteredError(t, name)
+	}
+	if _, ok := assignableValue(reflect.New(des.itemType), t); !ok {
+		return fmt.Errorf("type '%s' not match to item type '%s'", t, des.itemType)
+	}
+	return nil
+}
+
+// checkPooledField returns an error if the item of field f is Pooled, or holds
+// a Pooled item through the fields of the items created for it, while one of
+// owners outlives the scope. Items which outlive the scope end the chain, they
+// are checked on their own.
+func (c *Container) checkPooledField(owners []*ItemDescriptor, f injectFieldInfo, path map[*ItemDescriptor]bool) error {
+	if f.tagErr != nil || f.group || f.lazy || isBinder(f.fieldType) {
+		return nil
+	}
+	des, _ := c.lookupItem(f.fieldType, itemNameOf(f.itemName))
+	if des == nil || des.construct != nil || path[des] {
+		return nil
+	}
+	if des.lifetime == Pooled {
+		if err := c.lifetimes.checkPooledOwner(des, owners); err != nil {
+			return err
+		}
+	}
+	if des.instance != nil || !c.lifetimes.endsWithScope(des.lifetime) {
+		return nil
+	}
+
+	path[des] = true
+	defer delete(path, des)
+	owners = append(owners, des)
+	for _, f := range c.injectionFields(des.itemType) {
+		if err := c.checkPooledField(owners, f, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type DBOptions struct {
+	Host    string
+	Port    int
+	Timeout time.Duration `di.config:"connect-timeout"`
+	Replica []string
+	TLS     TLSOptions
+}
+
+type TLSOptions struct {
+	Enabled bool
+}
+
+type ConfigService struct {
+	host    string        `di.config:"db.host"`
+	port    uint16        `di.config:"db.port"`
+	ratio   float64       `di.config:"ratio"`
+	timeout time.Duration `di.config:"db.connect-timeout"`
+	missing string        `di.config:"missing,optional"`
+}
+
+type MissingConfigService struct {
+	missing string `di.config:"missing"`
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfig(t *testing.T) {
+	path := writeConfigFile(t, `{"db": {"host": "file", "port": 5432, "replica": ["a", "b"], "tls": {"enabled": true}}, "ratio": 0.5}`)
+	file, err := di.NewJSONFileSource(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(path) = %v; want %v", err, nil)
+	}
+
+	t.Setenv("TEST_DB_CONNECT_TIMEOUT", "3s")
+	constainer := di.NewContainer(di.WithConfigSources(
+		file,
+		di.NewMapSource(map[string]any{"db": map[string]any{"host": "memory"}}),
+		di.NewEnvSource("test"),
+	))
+	di.RegisterTransient[ConfigService](constainer, false)
+	di.RegisterTransient[MissingConfigService](constainer, false)
+	di.BindConfig[DBOptions](constainer, "db")
+
+	s, err := di.Resolve[ConfigService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[ConfigService](constainer) = %v,%v; want %v,%v", s, err, ConfigService{}, nil)
+	}
+	if s.host != "memory" || s.port != 5432 || s.ratio != 0.5 || s.timeout != 3*time.Second || s.missing != "" {
+		t.Errorf("s = %+v; want host %v, port %v, ratio %v, timeout %v", *s, "memory", 5432, 0.5, 3*time.Second)
+	}
+
+	db, err := di.Resolve[DBOptions](constainer)
+	if db == nil || err != nil {
+		t.Fatalf("Resolve[DBOptions](constainer) = %v,%v; want %v,%v", db, err, DBOptions{}, nil)
+	}
+	if db.Host != "memory" || db.Port != 5432 || db.Timeout != 3*time.Second || len(db.Replica) != 2 || !db.TLS.Enabled {
+		t.Errorf("db = %+v; want host %v, port %v, timeout %v, replica %v, tls %v", *db, "memory", 5432, 3*time.Second, []string{"a", "b"}, true)
+	}
+	if db2, _ := di.Resolve[DBOptions](constainer); db2 != db {
+		t.Errorf("Resolve[DBOptions](constainer) = %p; want %p", db2, db)
+	}
+
+	if s, err := di.Resolve[MissingConfigService](constainer); s != nil || err == nil {
+		t.Errorf("Resolve[MissingConfigService](constainer) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}
+
+func TestConfigInvalidValue(t *testing.T) {
+	constainer := di.NewContainer(di.WithConfigSources(di.NewMapSource(map[string]any{"db.port": "http"})))
+	di.BindConfig[DBOptions](constainer, "db")
+
+	if db, err := di.Resolve[DBOptions](constainer); db != nil || err == nil {
+		t.Errorf("Resolve[DBOptions](constainer) = %v,%v; want %v,%v", db, err, nil, "error")
+	}
+}
+
+type AppOptions struct {
+	Hosts  []string
+	Ports  []int
+	Labels LabelOptions
+}
+
+type LabelOptions struct {
+	Team string
+}
+
+func TestConfigTypedValues(t *testing.T) {
+	constainer := di.NewContainer(di.WithConfigSources(di.NewMapSource(map[string]any{
+		"app": map[string]any{
+			"hosts":  []string{"a", "b"},
+			"ports":  []int{80, 443},
+			"labels": map[string]string{"team": "core"},
+		},
+	})))
+	di.BindConfig[AppOptions](constainer, "app")
+
+	app, err := di.Resolve[AppOptions](constainer)
+	if app == nil || err != nil {
+		t.Fatalf("Resolve[AppOptions](constainer) = %v,%v; want %v,%v", app, err, AppOptions{}, nil)
+	}
+	if len(app.Hosts) != 2 || app.Hosts[0] != "a" || app.Hosts[1] != "b" {
+		t.Errorf("app.Hosts = %q; want %q", app.Hosts, []string{"a", "b"})
+	}
+	if len(app.Ports) != 2 || app.Ports[1] != 443 {
+		t.Errorf("app.Ports = %v; want %v", app.Ports, []int{80, 443})
+	}
+	if app.Labels.Team != "core" {
+		t.Errorf("app.Labels.Team = %v; want %v", app.Labels.Team, "core")
+	}
+}
+
+type FeatureOptions struct {
+	Enabled bool
+	Limit   int
+}
+
+type FeatureService struct {
+	options  di.Options[FeatureOptions]         `di.inject:""`
+	snapshot di.OptionsSnapshot[FeatureOptions] `di.inject:""`
+	monitor  di.OptionsMonitor[FeatureOptions]  `di.inject:""`
+}
+
+func TestOptions(t *testing.T) {
+	path := writeConfigFile(t, `{"feature": {"enabled": false, "limit": 1}}`)
+	file, err := di.NewJSONFileSource(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(path) = %v; want %v", err, nil)
+	}
+	stop := file.Watch(5 * time.Millisecond)
+	defer stop()
+
+	constainer := di.NewContainer(di.WithConfigSources(file))
+	di.BindConfig[FeatureOptions](constainer, "feature")
+	di.RegisterScoped[FeatureService](constainer, false)
+
+	scope, _ := constainer.NewScope()
+	s, err := di.Resolve[FeatureService](scope)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[FeatureService](scope) = %v,%v; want %v,%v", s, err, FeatureService{}, nil)
+	}
+	if s.options.Value().Limit != 1 || s.snapshot.Value().Limit != 1 || s.monitor.Value().Limit != 1 {
+		t.Errorf("limits = %v,%v,%v; want %v,%v,%v", s.options.Value().Limit, s.snapshot.Value().Limit, s.monitor.Value().Limit, 1, 1, 1)
+	}
+
+	changed := make(chan *FeatureOptions, 1)
+	s.monitor.OnChange(func(o *FeatureOptions) { changed <- o })
+
+	if err := os.WriteFile(path, []byte(`{"feature": {"enabled": true, "limit": 2}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case o := <-changed:
+		if !o.Enabled || o.Limit != 2 {
+			t.Errorf("OnChange value = %+v; want %+v", *o, FeatureOptions{Enabled: true, Limit: 2})
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnChange was not called after the config file changed")
+	}
+
+	if s.monitor.Value().Limit != 2 {
+		t.Errorf("s.monitor.Value().Limit = %v; want %v", s.monitor.Value().Limit, 2)
+	}
+	if s.options.Value().Limit != 1 || s.snapshot.Value().Limit != 1 {
+		t.Errorf("s.options, s.snapshot limits = %v,%v; want %v,%v", s.options.Value().Limit, s.snapshot.Value().Limit, 1, 1)
+	}
+
+	other, _ := constainer.NewScope()
+	s2, _ := di.Resolve[FeatureService](other)
+	if s2 == nil || s2.snapshot.Value().Limit != 2 || s2.options.Value().Limit != 1 {
+		t.Errorf("Resolve[FeatureService](other) = %+v; want snapshot limit %v, options limit %v", s2, 2, 1)
+	}
+}
+
+type LimitsOptions struct {
+	Max   int
+	Sizes []int
+}
+
+func TestConfigLargeNumbers(t *testing.T) {
+	path := writeConfigFile(t, `{"file": {"max": 10000000, "sizes": [10000000, 20000000]}}`)
+	file, err := di.NewJSONFileSource(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(path) = %v; want %v", err, nil)
+	}
+	constainer := di.NewContainer(di.WithConfigSources(file))
+	di.BindConfig[LimitsOptions](constainer, "file")
+
+	l, err := di.Resolve[LimitsOptions](constainer)
+	if l == nil || err != nil || l.Max != 10000000 || len(l.Sizes) != 2 || l.Sizes[1] != 20000000 {
+		t.Errorf("Resolve[LimitsOptions](constainer) = %+v,%v; want max %v, sizes %v", l, err, 10000000, []int{10000000, 20000000})
+	}
+
+	memory := di.NewContainer(di.WithConfigSources(
+		di.NewMapSource(map[string]any{"memory": map[string]any{"max": float64(10000000)}}),
+	))
+	di.BindConfig[LimitsOptions](memory, "memory")
+	if m, err := di.Resolve[LimitsOptions](memory); m == nil || err != nil || m.Max != 10000000 {
+		t.Errorf("Resolve[LimitsOptions](memory) = %+v,%v; want max %v", m, err, 10000000)
+	}
+}
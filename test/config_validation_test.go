@@ -0,0 +1,90 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type ServerOptions struct {
+	Host    string        `validate:"required"`
+	Port    int           `validate:"min=1,max=65535"`
+	Mode    string        `validate:"oneof=debug|release"`
+	Timeout time.Duration `validate:"max=1m"`
+	Names   []string      `di.config:"alias" validate:"min=1"`
+	TLS     ServerTLS
+}
+
+type ServerTLS struct {
+	Enabled bool
+	Cert    string
+}
+
+func (s *ServerTLS) Validate() error {
+	if s.Enabled && s.Cert == "" {
+		return errors.New("cert is required when tls is enabled")
+	}
+	return nil
+}
+
+func TestConfigValidation(t *testing.T) {
+	constainer := di.NewContainer(di.WithConfigSources(di.NewMapSource(map[string]any{
+		"server": map[string]any{"port": 70000, "mode": "test", "timeout": "2m", "tls": map[string]any{"enabled": true}},
+	})))
+	di.BindConfig[ServerOptions](constainer, "server")
+
+	s, err := di.Resolve[ServerOptions](constainer)
+	var validationErr *di.ValidationError
+	if s != nil || !errors.As(err, &validationErr) {
+		t.Fatalf("Resolve[ServerOptions](constainer) = %v,%v; want %v,%v", s, err, nil, "*di.ValidationError")
+	}
+
+	want := []string{"server.host", "server.port", "server.mode", "server.timeout", "server.alias", "server.tls"}
+	if len(validationErr.Violations) != len(want) {
+		t.Fatalf("Violations = %v; want keys %v", validationErr.Violations, want)
+	}
+	for i, v := range validationErr.Violations {
+		if v.Key != want[i] {
+			t.Errorf("Violations[%d].Key = %v; want %v", i, v.Key, want[i])
+		}
+	}
+
+	if err := constainer.Validate(); !errors.As(err, &validationErr) {
+		t.Errorf("constainer.Validate() = %v; want %v", err, "*di.ValidationError")
+	}
+}
+
+func TestConfigValidationValid(t *testing.T) {
+	constainer := di.NewContainer(di.WithConfigSources(di.NewMapSource(map[string]any{
+		"server": map[string]any{"host": "localhost", "port": 8080, "mode": "debug", "alias": "web", "tls": map[string]any{"enabled": true, "cert": "cert.pem"}},
+	})))
+	di.BindConfig[ServerOptions](constainer, "server")
+
+	if err := constainer.Validate(); err != nil {
+		t.Errorf("constainer.Validate() = %v; want %v", err, nil)
+	}
+	if s, err := di.Resolve[ServerOptions](constainer); s == nil || err != nil || s.Port != 8080 {
+		t.Errorf("Resolve[ServerOptions](constainer) = %v,%v; want port %v,%v", s, err, 8080, nil)
+	}
+}
+
+type ModeOptions struct {
+	mode  string `validate:"oneof=debug|release"`
+	level int    `validate:"required"`
+}
+
+func TestConfigValidationUnexported(t *testing.T) {
+	constainer := di.NewContainer(di.WithConfigSources(di.NewMapSource(map[string]any{"app.mode": "test"})))
+	di.BindConfig[ModeOptions](constainer, "app")
+
+	s, err := di.Resolve[ModeOptions](constainer)
+	var validationErr *di.ValidationError
+	if s != nil || !errors.As(err, &validationErr) || len(validationErr.Violations) != 2 {
+		t.Fatalf("Resolve[ModeOptions](constainer) = %v,%v; want %v,%v", s, err, nil, "2 violations")
+	}
+	if validationErr.Violations[0].Key != "app.mode" {
+		t.Errorf("Violations[0].Key = %v; want %v", validationErr.Violations[0].Key, "app.mode")
+	}
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type ThirdParty struct {
+	Logger *Service1
+	Client *Service2
+	Cache  *Service4
+	Tagged *Service1 `di.inject:""`
+}
+
+func TestConfigure(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterSingleton[Service2](constainer, false)
+	di.RegisterByName(constainer, "app-log", &Service1{id: 1}, false)
+	di.RegisterTransient[ThirdParty](constainer, false)
+
+	di.Configure[ThirdParty](constainer).
+		Field("Logger").Named("app-log").
+		Field("Client").
+		Field("Cache").Optional().
+		Skip("Tagged")
+
+	s, err := di.Resolve[ThirdParty](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[ThirdParty](constainer) = %v,%v; want %v,%v", s, err, ThirdParty{}, nil)
+	}
+
+	if s.Logger == nil || s.Logger.id != 1 {
+		t.Errorf("s.Logger = %v; want %v", s.Logger, Service1{id: 1})
+	}
+	if s.Client == nil {
+		t.Errorf("s.Client = %v; want %v", s.Client, Service2{})
+	}
+	if s.Cache != nil || s.Tagged != nil {
+		t.Errorf("s.Cache, s.Tagged = %v,%v; want %v,%v", s.Cache, s.Tagged, nil, nil)
+	}
+}
+
+func TestConfigureUnknownField(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error(`Configure[ThirdParty](constainer).Field("Missing") did not panic`)
+		}
+	}()
+	di.Configure[ThirdParty](di.NewContainer()).Field("Missing")
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type Credentials struct {
+	disposed bool
+}
+
+func (c *Credentials) Dispose() error {
+	c.disposed = true
+	return nil
+}
+
+type CredentialsClient struct {
+	credentials *Credentials `di.inject:""`
+}
+
+func TestExpiresAfter(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Credentials](constainer, false, di.ExpiresAfter(20*time.Millisecond))
+	di.RegisterTransient[CredentialsClient](constainer, false)
+
+	c1, err := di.Resolve[CredentialsClient](constainer)
+	if c1 == nil || err != nil {
+		t.Fatalf("Resolve[CredentialsClient](constainer) = %v,%v; want %v,%v", c1, err, CredentialsClient{}, nil)
+	}
+	if c2, _ := di.Resolve[CredentialsClient](constainer); c2.credentials != c1.credentials {
+		t.Errorf("c2.credentials = %p; want %p before expiry", c2.credentials, c1.credentials)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	c3, err := di.Resolve[CredentialsClient](constainer)
+	if c3 == nil || err != nil {
+		t.Fatalf("Resolve[CredentialsClient](constainer) = %v,%v; want %v,%v", c3, err, CredentialsClient{}, nil)
+	}
+	if c3.credentials == c1.credentials {
+		t.Errorf("c3.credentials = %p; want a new instance after expiry", c3.credentials)
+	}
+	if !c1.credentials.disposed {
+		t.Errorf("expired credentials disposed = %v; want %v", false, true)
+	}
+
+	constainer.Dispose()
+	if !c3.credentials.disposed {
+		t.Errorf("current credentials disposed = %v; want %v", false, true)
+	}
+}
+
+type CredentialsUser struct {
+	credentials *Credentials `di.inject:""`
+	during      func(*CredentialsUser)
+}
+
+func (u *CredentialsUser) Inject() {
+	u.during(u)
+}
+
+func TestExpiresAfterInFlight(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Credentials](constainer, false, di.ExpiresAfter(10*time.Millisecond))
+
+	var old *Credentials
+	di.RegisterFactory(constainer, di.Transient, func(di.Container) *CredentialsUser {
+		return &CredentialsUser{during: func(u *CredentialsUser) {
+			old = u.credentials
+			time.Sleep(20 * time.Millisecond)
+			// Another resolution replaces the expired instance while this one
+			// still uses it.
+			if c, _ := di.Resolve[Credentials](constainer); c == old {
+				t.Errorf("Resolve[Credentials](constainer) = %p; want a new instance", c)
+			}
+			if old.disposed {
+				t.Errorf("expired credentials disposed while in use")
+			}
+		}}
+	}, false)
+
+	if _, err := di.Resolve[CredentialsUser](constainer); err != nil {
+		t.Fatalf("Resolve[CredentialsUser](constainer) = %v; want %v", err, nil)
+	}
+	if !old.disposed {
+		t.Errorf("expired credentials disposed = %v; want %v", false, true)
+	}
+}
+
+func TestExpiresAfterConcurrent(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Credentials](constainer, false, di.ExpiresAfter(time.Microsecond))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if c, err := di.Resolve[Credentials](constainer); c == nil || err != nil {
+					t.Errorf("Resolve[Credentials](constainer) = %v,%v; want %v,%v", c, err, Credentials{}, nil)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
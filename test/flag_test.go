@@ -0,0 +1,84 @@
+package test
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type CLIOptions struct {
+	port    int           `di.flag:"port,default=8080,usage=port to listen on, in the range 1-65535"`
+	verbose bool          `di.flag:"verbose"`
+	timeout time.Duration `di.flag:"timeout,default=1s"`
+	name    string        `di.flag:"name"`
+}
+
+type CLICommand struct {
+	options *CLIOptions `di.inject:""`
+	port    int         `di.flag:"port"`
+}
+
+func TestFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	constainer := di.NewContainer(di.WithFlagSet(fs))
+	di.RegisterSingleton[CLIOptions](constainer, false)
+	di.RegisterTransient[CLICommand](constainer, false)
+
+	if err := constainer.Validate(); err != nil {
+		t.Fatalf("constainer.Validate() = %v; want %v", err, nil)
+	}
+
+	port := fs.Lookup("port")
+	if port == nil || port.Usage != "port to listen on, in the range 1-65535" || port.DefValue != "8080" {
+		t.Errorf(`fs.Lookup("port") = %+v; want usage and default`, port)
+	}
+
+	if err := fs.Parse([]string{"-verbose", "-port", "9090"}); err != nil {
+		t.Fatalf("fs.Parse() = %v; want %v", err, nil)
+	}
+
+	cmd, err := di.Resolve[CLICommand](constainer)
+	if cmd == nil || err != nil {
+		t.Fatalf("Resolve[CLICommand](constainer) = %v,%v; want %v,%v", cmd, err, CLICommand{}, nil)
+	}
+	o := cmd.options
+	if o.port != 9090 || !o.verbose || o.timeout != time.Second || o.name != "" || cmd.port != 9090 {
+		t.Errorf("options = %+v, cmd.port = %v; want port %v, verbose %v, timeout %v", *o, cmd.port, 9090, true, time.Second)
+	}
+
+	if err := fs.Parse([]string{"-port", "http"}); err == nil {
+		t.Errorf(`fs.Parse("-port", "http") = %v; want %v`, err, "error")
+	}
+}
+
+func TestFlagsWithoutValidate(t *testing.T) {
+	constainer := di.NewContainer(di.WithFlagSet(flag.NewFlagSet("test", flag.ContinueOnError)))
+	di.RegisterSingleton[CLIOptions](constainer, false)
+
+	if o, err := di.Resolve[CLIOptions](constainer); o != nil || err == nil {
+		t.Errorf("Resolve[CLIOptions](constainer) = %v,%v; want %v,%v", o, err, nil, "error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterTransient[Service3](constainer, false)
+	di.RegisterTransient[Service4](constainer, false)
+	di.RegisterTransient[OptionalService](constainer, false)
+
+	err := constainer.Validate()
+	if err == nil {
+		t.Fatalf("constainer.Validate() = %v; want %v", err, "error")
+	}
+
+	di.RegisterByName(constainer, "test", &Service1{}, false)
+	di.RegisterTransient[Service1](constainer, false)
+	if err := constainer.Validate(); err != nil {
+		t.Errorf("constainer.Validate() = %v; want %v", err, nil)
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type UnitOfWork struct {
+	id int
+}
+
+type OrderRepository struct {
+	uow *UnitOfWork `di.inject:""`
+}
+
+type CustomerRepository struct {
+	uow *UnitOfWork `di.inject:""`
+}
+
+type PlaceOrderCommand struct {
+	orders    *OrderRepository        `di.inject:""`
+	customers *CustomerRepository     `di.inject:""`
+	later     di.Provider[UnitOfWork] `di.inject:""`
+}
+
+func TestPerResolve(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterPerResolve[UnitOfWork](constainer, false)
+	di.RegisterTransient[OrderRepository](constainer, false)
+	di.RegisterTransient[CustomerRepository](constainer, false)
+	di.RegisterTransient[PlaceOrderCommand](constainer, false)
+
+	cmd, err := di.Resolve[PlaceOrderCommand](constainer)
+	if cmd == nil || err != nil {
+		t.Fatalf("Resolve[PlaceOrderCommand](constainer) = %v,%v; want %v,%v", cmd, err, PlaceOrderCommand{}, nil)
+	}
+	if cmd.orders.uow == nil || cmd.orders.uow != cmd.customers.uow {
+		t.Errorf("orders.uow, customers.uow = %p,%p; want the same instance", cmd.orders.uow, cmd.customers.uow)
+	}
+
+	other, _ := di.Resolve[PlaceOrderCommand](constainer)
+	if other.orders.uow == cmd.orders.uow {
+		t.Errorf("other.orders.uow = %p; want a different instance than %p", other.orders.uow, cmd.orders.uow)
+	}
+	if uow, _ := cmd.later.Get(); uow == cmd.orders.uow {
+		t.Errorf("cmd.later.Get() = %p; want a different instance than %p", uow, cmd.orders.uow)
+	}
+
+	err = di.Invoke(constainer, func(orders *OrderRepository, customers *CustomerRepository) {
+		if orders.uow != customers.uow {
+			t.Errorf("orders.uow, customers.uow = %p,%p; want the same instance", orders.uow, customers.uow)
+		}
+	})
+	if err != nil {
+		t.Errorf("Invoke(constainer, fn) = %v; want %v", err, nil)
+	}
+}
+
+type OptionalUnitCommand struct {
+	uow      *UnitOfWork             `di.inject:""`
+	optional di.Optional[UnitOfWork] `di.inject:""`
+}
+
+func TestPerResolveOptional(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterPerResolve[UnitOfWork](constainer, false)
+	di.RegisterTransient[OptionalUnitCommand](constainer, false)
+
+	cmd, err := di.Resolve[OptionalUnitCommand](constainer)
+	if cmd == nil || err != nil {
+		t.Fatalf("Resolve[OptionalUnitCommand](constainer) = %v,%v; want %v,%v", cmd, err, OptionalUnitCommand{}, nil)
+	}
+	if uow, ok := cmd.optional.Get(); !ok || uow != cmd.uow {
+		t.Errorf("cmd.optional.Get() = %p,%v; want %p,%v", uow, ok, cmd.uow, true)
+	}
+}
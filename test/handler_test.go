@@ -0,0 +1,56 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type GreetingHandler struct {
+	request *http.Request `di.inject:""`
+	user    *RequestUser  `di.inject:""`
+}
+
+func (h *GreetingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.request != r {
+		http.Error(w, "request not injected", http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte("hello " + h.user.name))
+}
+
+func TestHandler(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterScoped[GreetingHandler](constainer, false)
+
+	middleware := di.HTTPMiddleware(constainer, di.WithScopeValues(func(r *http.Request) ([]any, error) {
+		return []any{&RequestUser{name: "alice"}}, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	middleware(di.Handler[GreetingHandler]()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello alice" {
+		t.Errorf("response = %v,%q; want %v,%q", rec.Code, rec.Body.String(), http.StatusOK, "hello alice")
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	constainer := di.NewContainer()
+
+	var handlerErr error
+	handler := di.Handler[GreetingHandler](di.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handlerErr = err
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	di.HTTPMiddleware(constainer)(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusTeapot || handlerErr == nil {
+		t.Errorf("response = %v,%v; want %v,%v", rec.Code, handlerErr, http.StatusTeapot, errors.New("type 'GreetingHandler' not registered"))
+	}
+}
@@ -0,0 +1,267 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type Greeter interface {
+	Greet() string
+}
+
+type EnglishGreeter struct{}
+
+func (g *EnglishGreeter) Greet() string { return "hello" }
+
+type Middlewares []string
+
+type ValueService struct {
+	greeter     Greeter          `di.inject:""`
+	service1    Service1         `di.inject:""`
+	port        int              `di.inject:"port"`
+	host        string           `di.inject:"host"`
+	timeout     time.Duration    `di.inject:"timeout"`
+	now         func() time.Time `di.inject:"now"`
+	middlewares Middlewares      `di.inject:""`
+}
+
+type MismatchService struct {
+	port string `di.inject:"port"`
+}
+
+func TestInjectValues(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[EnglishGreeter](constainer, false)
+	di.RegisterInstance(constainer, &Service1{id: 1}, false)
+	di.RegisterByName(constainer, "port", 8080, false)
+	di.RegisterByName(constainer, "host", "localhost", false)
+	di.RegisterByName(constainer, "timeout", 5*time.Second, false)
+	di.RegisterByName(constainer, "now", time.Now, false)
+	constainer.RegisterInstance(Middlewares{"auth", "log"}, false)
+	di.RegisterTransient[ValueService](constainer, false)
+	di.RegisterTransient[MismatchService](constainer, false)
+
+	s, err := di.Resolve[ValueService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[ValueService](constainer) = %v,%v; want %v,%v", s, err, ValueService{}, nil)
+	}
+
+	if s.greeter == nil || s.greeter.Greet() != "hello" {
+		t.Errorf("s.greeter = %v; want %v", s.greeter, &EnglishGreeter{})
+	}
+
+	s.service1.id = 2
+	if s1, _ := di.Resolve[Service1](constainer); s.service1.id == s1.id {
+		t.Errorf("s.service1.id = %v; want a copy of %v", s.service1.id, s1.id)
+	}
+
+	if s.port != 8080 || s.host != "localhost" || s.timeout != 5*time.Second {
+		t.Errorf("s.port, s.host, s.timeout = %v,%v,%v; want %v,%v,%v", s.port, s.host, s.timeout, 8080, "localhost", 5*time.Second)
+	}
+	if s.now == nil {
+		t.Error("s.now = nil; want time.Now")
+	}
+	if len(s.middlewares) != 2 {
+		t.Errorf("s.middlewares = %v; want %v", s.middlewares, Middlewares{"auth", "log"})
+	}
+
+	if s, err := di.Resolve[MismatchService](constainer); s != nil || err == nil {
+		t.Errorf("Resolve[MismatchService](constainer) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}
+
+type FrenchGreeter struct{}
+
+func (g *FrenchGreeter) Greet() string { return "bonjour" }
+
+func TestInjectAmbiguousInterface(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[EnglishGreeter](constainer, false)
+	di.RegisterSingleton[FrenchGreeter](constainer, false)
+
+	err := di.Invoke(constainer, func(g Greeter) {})
+	if err == nil {
+		t.Errorf("Invoke(constainer, func(g Greeter) {}) = %v; want %v", err, "error")
+	}
+}
+
+type WriterService struct {
+	writer io.Writer `di.inject:",optional"`
+}
+
+func TestInjectInterfaceIgnoresProvided(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterScoped[WriterService](constainer, false)
+
+	handler := di.HTTPMiddleware(constainer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := di.ResolveFromContext[WriterService](r.Context())
+		if s == nil || err != nil {
+			t.Fatalf("ResolveFromContext[WriterService](ctx) = %v,%v; want %v,%v", s, err, WriterService{}, nil)
+		}
+		// The response writer provided to the request scope is not an
+		// implementation of io.Writer registered in the container.
+		if s.writer != nil {
+			t.Errorf("s.writer = %T; want %v", s.writer, nil)
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+type Clock func() time.Time
+
+type Routes map[string]string
+
+type Port int
+
+type RouterService struct {
+	clock  Clock   `di.inject:""`
+	routes *Routes `di.inject:""`
+	port   Port    `di.inject:""`
+}
+
+func TestRegisterNonStruct(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterFactory(constainer, di.Singleton, func(c di.Container) *Clock {
+		clock := Clock(time.Now)
+		return &clock
+	}, false)
+	constainer.RegisterFactory(reflect.TypeOf(Routes{}), di.Transient, func(c di.Container) any {
+		return Routes{"/": "index"}
+	}, false)
+	constainer.RegisterInstance(Port(8080), false)
+	di.RegisterTransient[RouterService](constainer, false)
+
+	s, err := di.Resolve[RouterService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[RouterService](constainer) = %v,%v; want %v,%v", s, err, RouterService{}, nil)
+	}
+	if s.clock == nil || s.routes == nil || (*s.routes)["/"] != "index" || s.port != 8080 {
+		t.Errorf("s.clock, s.routes, s.port = %v,%v,%v; want %v,%v,%v", s.clock != nil, s.routes, s.port, true, Routes{"/": "index"}, 8080)
+	}
+
+	port, err := di.Resolve[Port](constainer)
+	if port == nil || err != nil || *port != 8080 {
+		t.Errorf("Resolve[Port](constainer) = %v,%v; want %v,%v", port, err, 8080, nil)
+	}
+
+	other := di.NewContainer()
+	di.RegisterTransient[Port](other, false)
+	port, err = di.Resolve[Port](other)
+	if port == nil || err != nil || *port != 0 {
+		t.Errorf("Resolve[Port](other) = %v,%v; want %v,%v", port, err, 0, nil)
+	}
+}
+
+type BaseHandler struct {
+	service1 *Service1 `di.inject:""`
+	greeter  Greeter   `di.inject:""`
+}
+
+type AuditBase struct {
+	audit *Service1 `di.inject:"audit"`
+}
+
+type UserHandler struct {
+	BaseHandler
+	*AuditBase
+	service1 *Service1 `di.inject:"test"`
+}
+
+func TestInjectEmbedded(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterSingleton[EnglishGreeter](constainer, false)
+	di.RegisterByName(constainer, "test", &Service1{id: 1}, false)
+	di.RegisterByName(constainer, "audit", &Service1{id: 2}, false)
+	di.RegisterTransient[UserHandler](constainer, false)
+
+	h, err := di.Resolve[UserHandler](constainer)
+	if h == nil || err != nil {
+		t.Fatalf("Resolve[UserHandler](constainer) = %v,%v; want %v,%v", h, err, UserHandler{}, nil)
+	}
+
+	if h.service1 == nil || h.service1.id != 1 {
+		t.Errorf("h.service1 = %v; want %v", h.service1, Service1{id: 1})
+	}
+	if h.greeter == nil {
+		t.Errorf("h.greeter = %v; want %v", h.greeter, &EnglishGreeter{})
+	}
+
+	// Shadowed by UserHandler.service1.
+	if h.BaseHandler.service1 != nil {
+		t.Errorf("h.BaseHandler.service1 = %v; want %v", h.BaseHandler.service1, nil)
+	}
+	if h.AuditBase == nil || h.audit == nil || h.audit.id != 2 {
+		t.Errorf("h.AuditBase = %v; want %v", h.AuditBase, &AuditBase{audit: &Service1{id: 2}})
+	}
+}
+
+func TestInjectInto(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterByName(constainer, "test", &Service1{id: 1}, false)
+
+	s4 := &Service4{id: 4}
+	if err := di.InjectInto(constainer, s4); err != nil {
+		t.Fatalf("InjectInto(constainer, s4) = %v; want %v", err, nil)
+	}
+	if s4.service1 == nil || s4.service1.id != 1 || s4.id != 4 {
+		t.Errorf("s4 = %v; want service1 %v, id %v", s4, Service1{id: 1}, 4)
+	}
+
+	if err := constainer.InjectInto(Service4{}); err == nil {
+		t.Errorf("constainer.InjectInto(Service4{}) = %v; want %v", err, "error")
+	}
+}
+
+func TestInjectOnResolve(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterInstance(constainer, &Service5{id: 5}, false, di.InjectOnResolve())
+	di.RegisterByName(constainer, "named", &Service5{id: 6}, false, di.InjectOnResolve())
+	di.RegisterInstance(constainer, &ValueService{}, false)
+
+	s5, err := di.Resolve[Service5](constainer)
+	if s5 == nil || err != nil || s5.service1 == nil || s5.id != 5 {
+		t.Errorf("Resolve[Service5](constainer) = %v,%v; want service1 %v, id %v", s5, err, Service1{}, 5)
+	}
+
+	named, err := di.ResolveByName[Service5](constainer, "named")
+	if named == nil || err != nil || named.service1 == nil {
+		t.Errorf("ResolveByName[Service5](constainer, \"named\") = %v,%v; want service1 %v", named, err, Service1{})
+	}
+
+	// Instances are stored untouched without the option.
+	vs, err := di.Resolve[ValueService](constainer)
+	if vs == nil || err != nil || vs.greeter != nil {
+		t.Errorf("Resolve[ValueService](constainer) = %v,%v; want %v,%v", vs, err, ValueService{}, nil)
+	}
+}
+
+func TestInjectOnResolveConcurrent(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterInstance(constainer, &Service1{id: 1}, false)
+	di.RegisterInstance(constainer, &Service5{id: 5}, false, di.InjectOnResolve())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scope, _ := constainer.NewScope()
+			defer scope.Dispose()
+			s5, err := di.Resolve[Service5](scope)
+			if s5 == nil || err != nil || s5.service1 == nil {
+				t.Errorf("Resolve[Service5](scope) = %v,%v; want service1 %v", s5, err, Service1{id: 1})
+			}
+		}()
+	}
+	wg.Wait()
+}
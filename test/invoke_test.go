@@ -0,0 +1,96 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+func TestInvoke(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterTransient[Service5](constainer, false)
+
+	called := false
+	err := di.Invoke(constainer, func(s1 *Service1, s5 Service5) error {
+		called = true
+		if s1 == nil || s5.service1 != s1 {
+			t.Errorf("s1, s5.service1 = %v,%v; want same instance", s1, s5.service1)
+		}
+		return nil
+	})
+	if err != nil || !called {
+		t.Errorf("Invoke(constainer, fn) = %v, called %v; want %v, called %v", err, called, nil, true)
+	}
+
+	want := errors.New("failed")
+	if err := di.Invoke(constainer, func(s1 *Service1) error { return want }); err != want {
+		t.Errorf("Invoke(constainer, fn) = %v; want %v", err, want)
+	}
+
+	if err := di.Invoke(constainer, func(s2 *Service2) {}); err == nil {
+		t.Errorf("Invoke(constainer, fn) = %v; want %v", err, "error")
+	}
+
+	err = di.Invoke(constainer, func(s1 *Service1, s2 *Service2) {
+		if s2 != nil {
+			t.Errorf("s2 = %v; want %v", s2, nil)
+		}
+	}, di.OptionalParams(1))
+	if err != nil {
+		t.Errorf("Invoke(constainer, fn, OptionalParams(1)) = %v; want %v", err, nil)
+	}
+
+	if err := di.Invoke(constainer, 1); err == nil {
+		t.Errorf("Invoke(constainer, 1) = %v; want %v", err, "error")
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterScoped[Service2](constainer, false)
+
+	var scoped *Service2
+	handler := di.HandlerFunc(func(w http.ResponseWriter, r *http.Request, s2 *Service2) error {
+		scoped = s2
+		return errors.New("failed")
+	}, di.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	rec := httptest.NewRecorder()
+	di.HTTPMiddleware(constainer)(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if scoped == nil || rec.Code != http.StatusBadRequest {
+		t.Errorf("s2, rec.Code = %v,%v; want %v,%v", scoped, rec.Code, Service2{}, http.StatusBadRequest)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("HandlerFunc(func(s2 *Service2) {}) did not panic")
+		}
+	}()
+	di.HandlerFunc(func(s2 *Service2) {})
+}
+
+func TestHandlerFuncOptionalParams(t *testing.T) {
+	constainer := di.NewContainer()
+
+	called := false
+	handler := di.HandlerFunc(func(w http.ResponseWriter, r *http.Request, s1 *Service1) {
+		called = true
+		if s1 != nil {
+			t.Errorf("s1 = %v; want %v", s1, nil)
+		}
+	}, di.WithInvokeOptions(di.OptionalParams(2)))
+
+	rec := httptest.NewRecorder()
+	di.HTTPMiddleware(constainer)(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !called || rec.Code != http.StatusOK {
+		t.Errorf("called, rec.Code = %v,%v; want %v,%v", called, rec.Code, true, http.StatusOK)
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type LazyService struct {
+	service1 di.Lazy[Service1]     `di.inject:""`
+	named    di.Lazy[Service1]     `di.inject:"test"`
+	provider di.Provider[Service1] `di.inject:""`
+}
+
+type CycleA struct {
+	b di.Lazy[CycleB] `di.inject:""`
+}
+
+type CycleB struct {
+	a *CycleA `di.inject:""`
+}
+
+func TestLazy(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterTransient[Service1](constainer, false)
+	di.RegisterByName(constainer, "test", &Service1{id: 1}, false)
+	di.RegisterTransient[LazyService](constainer, false)
+
+	s, err := di.Resolve[LazyService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[LazyService](constainer) = %v,%v; want %v,%v", s, err, LazyService{}, nil)
+	}
+
+	s1, err := s.service1.Get()
+	if s1 == nil || err != nil {
+		t.Fatalf("s.service1.Get() = %v,%v; want %v,%v", s1, err, Service1{}, nil)
+	}
+	if s12, _ := s.service1.Get(); s12 != s1 {
+		t.Errorf("s.service1.Get() = %p; want %p", s12, s1)
+	}
+	if named, _ := s.named.Get(); named == nil || named.id != 1 {
+		t.Errorf("s.named.Get() = %v; want %v", named, Service1{id: 1})
+	}
+
+	p1, _ := s.provider.Get()
+	p2, _ := s.provider.Get()
+	if p1 == nil || p1 == p2 {
+		t.Errorf("s.provider.Get() = %p,%p; want different instances", p1, p2)
+	}
+
+	var unbound di.Lazy[Service1]
+	if v, err := unbound.Get(); v != nil || err == nil {
+		t.Errorf("unbound.Get() = %v,%v; want %v,%v", v, err, nil, "error")
+	}
+}
+
+func TestLazyCycle(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[CycleA](constainer, false)
+	di.RegisterSingleton[CycleB](constainer, false)
+
+	a, err := di.Resolve[CycleA](constainer)
+	if a == nil || err != nil {
+		t.Fatalf("Resolve[CycleA](constainer) = %v,%v; want %v,%v", a, err, CycleA{}, nil)
+	}
+
+	b, err := a.b.Get()
+	if b == nil || err != nil || b.a != a {
+		t.Errorf("a.b.Get() = %v,%v; want b.a %p", b, err, a)
+	}
+}
+
+type LazySingleton struct {
+	lazy     di.Lazy[Service1]         `di.inject:""`
+	provider di.Provider[Service1]     `di.inject:""`
+	fn       func() (*Service1, error) `di.inject:",lazy"`
+}
+
+func TestLazySingletonOutlivesScope(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterTransient[Service1](constainer, false)
+	di.RegisterSingleton[LazySingleton](constainer, false)
+
+	scope, _ := constainer.NewScope()
+	s, err := di.Resolve[LazySingleton](scope)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[LazySingleton](scope) = %v,%v; want %v,%v", s, err, LazySingleton{}, nil)
+	}
+	scope.Dispose()
+
+	if s1, err := s.lazy.Get(); s1 == nil || err != nil {
+		t.Errorf("s.lazy.Get() = %v,%v; want %v,%v", s1, err, Service1{}, nil)
+	}
+	if s1, err := s.provider.Get(); s1 == nil || err != nil {
+		t.Errorf("s.provider.Get() = %v,%v; want %v,%v", s1, err, Service1{}, nil)
+	}
+	if s1, err := s.fn(); s1 == nil || err != nil {
+		t.Errorf("s.fn() = %v,%v; want %v,%v", s1, err, Service1{}, nil)
+	}
+}
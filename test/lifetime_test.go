@@ -0,0 +1,221 @@
+package test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+const PerTenant di.Lifetime = "per-tenant"
+
+type tenantKey struct{}
+
+// TenantLifetime shares instances between the scopes of the same tenant.
+type TenantLifetime struct {
+	mu        sync.Mutex
+	instances map[string]map[reflect.Type]any
+	disposed  int
+}
+
+func (l *TenantLifetime) Resolve(r di.Resolution, create func() (any, error)) (any, error) {
+	tenant, _ := r.Scope().Context().Value(tenantKey{}).(string)
+	item := r.Item()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ins, ok := l.instances[tenant][item.ItemType()]; ok {
+		return ins, nil
+	}
+	ins, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if l.instances[tenant] == nil {
+		l.instances[tenant] = make(map[reflect.Type]any)
+	}
+	l.instances[tenant][item.ItemType()] = ins
+	return ins, nil
+}
+
+func (l *TenantLifetime) Dispose(scope *di.Container) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.disposed++
+	return nil
+}
+
+type TenantCache struct {
+	entries map[string]string
+}
+
+func TestRegisterLifetime(t *testing.T) {
+	constainer := di.NewContainer()
+	manager := &TenantLifetime{instances: make(map[string]map[reflect.Type]any)}
+	if err := constainer.RegisterLifetime(PerTenant, manager, true); err != nil {
+		t.Fatalf("RegisterLifetime(PerTenant) = %v; want %v", err, nil)
+	}
+	if err := constainer.RegisterLifetime(di.Singleton, manager, true); err == nil {
+		t.Errorf("RegisterLifetime(di.Singleton) = %v; want %v", err, "error")
+	}
+	constainer.RegisterType(reflect.TypeOf(TenantCache{}), PerTenant, false)
+
+	acme1, _ := constainer.NewScopeContext(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	acme2, _ := constainer.NewScopeContext(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	other, _ := constainer.NewScopeContext(context.WithValue(context.Background(), tenantKey{}, "other"))
+
+	c1, err := di.Resolve[TenantCache](acme1)
+	if c1 == nil || err != nil {
+		t.Fatalf("Resolve[TenantCache](acme1) = %v,%v; want %v,%v", c1, err, TenantCache{}, nil)
+	}
+	if c2, _ := di.Resolve[TenantCache](acme2); c2 != c1 {
+		t.Errorf("Resolve[TenantCache](acme2) = %p; want %p", c2, c1)
+	}
+	if c3, _ := di.Resolve[TenantCache](other); c3 == c1 {
+		t.Errorf("Resolve[TenantCache](other) = %p; want a different instance than %p", c3, c1)
+	}
+
+	acme1.Dispose()
+	if manager.disposed != 1 {
+		t.Errorf("manager.disposed = %v; want %v", manager.disposed, 1)
+	}
+}
+
+func TestUnregisteredLifetime(t *testing.T) {
+	constainer := di.NewContainer()
+	constainer.RegisterType(reflect.TypeOf(TenantCache{}), PerTenant, false)
+
+	if c, err := di.Resolve[TenantCache](constainer); c != nil || err == nil {
+		t.Errorf("Resolve[TenantCache](constainer) = %v,%v; want %v,%v", c, err, nil, "error")
+	}
+	if err := constainer.Validate(); err == nil {
+		t.Errorf("constainer.Validate() = %v; want %v", err, "error")
+	}
+}
+
+const PerConnection di.Lifetime = "per-connection"
+
+// ConnectionLifetime keeps one instance per scope, keyed by the scope itself.
+type ConnectionLifetime struct {
+	mu        sync.Mutex
+	instances map[*di.Container]any
+}
+
+func (l *ConnectionLifetime) Resolve(r di.Resolution, create func() (any, error)) (any, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ins, ok := l.instances[r.Scope()]; ok {
+		return ins, nil
+	}
+	ins, err := create()
+	if err != nil {
+		return nil, err
+	}
+	l.instances[r.Scope()] = ins
+	return ins, nil
+}
+
+func (l *ConnectionLifetime) Dispose(scope *di.Container) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.instances, scope)
+	return nil
+}
+
+func (l *ConnectionLifetime) EndsWithScope() bool {
+	return true
+}
+
+func TestLifetimeKeyedByScope(t *testing.T) {
+	constainer := di.NewContainer()
+	manager := &ConnectionLifetime{instances: make(map[*di.Container]any)}
+	constainer.RegisterLifetime(PerConnection, manager, false)
+	constainer.RegisterType(reflect.TypeOf(TenantCache{}), PerConnection, false)
+
+	scope, _ := constainer.NewScope()
+	c1, err := di.Resolve[TenantCache](scope)
+	if c1 == nil || err != nil {
+		t.Fatalf("Resolve[TenantCache](scope) = %v,%v; want %v,%v", c1, err, TenantCache{}, nil)
+	}
+	if c2, _ := di.Resolve[TenantCache](scope); c2 != c1 {
+		t.Errorf("Resolve[TenantCache](scope) = %p; want %p", c2, c1)
+	}
+	if len(manager.instances) != 1 {
+		t.Errorf("len(manager.instances) = %v; want %v", len(manager.instances), 1)
+	}
+
+	scope.Dispose()
+	if len(manager.instances) != 0 {
+		t.Errorf("len(manager.instances) = %v after Dispose; want %v", len(manager.instances), 0)
+	}
+}
+
+type ConnectionBuffer struct {
+	buffer *PooledBuffer `di.inject:""`
+}
+
+func TestScopeBoundLifetimePooledOwner(t *testing.T) {
+	constainer := di.NewContainer()
+	constainer.RegisterLifetime(PerConnection, &ConnectionLifetime{instances: make(map[*di.Container]any)}, false)
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterPooled[PooledBuffer](constainer, false)
+	constainer.RegisterType(reflect.TypeOf(ConnectionBuffer{}), PerConnection, false)
+
+	if err := constainer.Validate(); err != nil {
+		t.Errorf("constainer.Validate() = %v; want %v", err, nil)
+	}
+	scope, _ := constainer.NewScope()
+	defer scope.Dispose()
+	if b, err := di.Resolve[ConnectionBuffer](scope); b == nil || b.buffer == nil || err != nil {
+		t.Errorf("Resolve[ConnectionBuffer](scope) = %v,%v; want buffer injected", b, err)
+	}
+}
+
+const PerProcess di.Lifetime = "per-process"
+
+// ProcessLifetime keeps one instance per item descriptor, which is shared by
+// the master container and its scopes.
+type ProcessLifetime struct {
+	mu        sync.Mutex
+	instances map[*di.ItemDescriptor]any
+	setup     int
+}
+
+func (l *ProcessLifetime) Resolve(r di.Resolution, create func() (any, error)) (any, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ins, ok := l.instances[r.Item()]; ok {
+		return ins, nil
+	}
+	ins, err := create()
+	if err != nil {
+		return nil, err
+	}
+	l.instances[r.Item()] = ins
+	return ins, nil
+}
+
+func (l *ProcessLifetime) Dispose(*di.Container) error { return nil }
+
+func (l *ProcessLifetime) SharedWithScopes() bool { return true }
+
+func (l *ProcessLifetime) Setup(*di.ItemDescriptor) { l.setup++ }
+
+func TestSharedLifetime(t *testing.T) {
+	constainer := di.NewContainer()
+	manager := &ProcessLifetime{instances: make(map[*di.ItemDescriptor]any)}
+	constainer.RegisterLifetime(PerProcess, manager, false)
+	constainer.RegisterType(reflect.TypeOf(TenantCache{}), PerProcess, false)
+	if manager.setup != 1 {
+		t.Errorf("manager.setup = %v; want %v", manager.setup, 1)
+	}
+
+	scope1, _ := constainer.NewScope()
+	scope2, _ := constainer.NewScope()
+	c1, _ := di.Resolve[TenantCache](scope1)
+	if c2, _ := di.Resolve[TenantCache](scope2); c1 == nil || c2 != c1 {
+		t.Errorf("Resolve[TenantCache](scope2) = %p; want %p", c2, c1)
+	}
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type SetterService struct {
+	service1 *Service1 `di.inject:""`
+	greeter  Greeter
+	service2 *Service2
+	calls    []string
+}
+
+func (s *SetterService) Inject(g Greeter) {
+	if s.service1 == nil {
+		s.calls = append(s.calls, "fields not injected")
+	}
+	s.greeter = g
+	s.calls = append(s.calls, "Inject")
+}
+
+func (s *SetterService) SetService2(s2 *Service2) error {
+	s.service2 = s2
+	s.calls = append(s.calls, "SetService2")
+	return nil
+}
+
+type FailingSetterService struct{}
+
+func (s *FailingSetterService) Inject(s1 *Service1) error {
+	return errors.New("failed")
+}
+
+func TestMethodInjection(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterSingleton[Service2](constainer, false)
+	di.RegisterSingleton[EnglishGreeter](constainer, false)
+	di.RegisterTransient[SetterService](constainer, false, di.InjectMethods("SetService2"))
+	di.RegisterTransient[FailingSetterService](constainer, false)
+
+	s, err := di.Resolve[SetterService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[SetterService](constainer) = %v,%v; want %v,%v", s, err, SetterService{}, nil)
+	}
+
+	if s.greeter == nil || s.service2 == nil {
+		t.Errorf("s.greeter, s.service2 = %v,%v; want %v,%v", s.greeter, s.service2, &EnglishGreeter{}, &Service2{})
+	}
+	if len(s.calls) != 2 || s.calls[0] != "Inject" || s.calls[1] != "SetService2" {
+		t.Errorf("s.calls = %v; want %v", s.calls, []string{"Inject", "SetService2"})
+	}
+
+	if f, err := di.Resolve[FailingSetterService](constainer); f != nil || err == nil {
+		t.Errorf("Resolve[FailingSetterService](constainer) = %v,%v; want %v,%v", f, err, nil, "error")
+	}
+
+	existing := &SetterService{}
+	if err := di.InjectInto(constainer, existing); err != nil || existing.greeter == nil || existing.service2 != nil {
+		t.Errorf("InjectInto(constainer, existing) = %v; want greeter set and service2 %v", err, nil)
+	}
+}
+
+func TestMethodInjectionMissing(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterTransient[Service2](constainer, false, di.InjectMethods("SetMissing"))
+
+	if s, err := di.Resolve[Service2](constainer); s != nil || err == nil {
+		t.Errorf("Resolve[Service2](constainer) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}
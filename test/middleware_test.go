@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -28,9 +29,117 @@ func TestMiddleware(t *testing.T) {
 		}
 	}))
 	server := httptest.NewServer(middleware)
+	defer server.Close()
 
 	_, err := http.Get(server.URL)
 	if err != nil {
 		t.Errorf(`_, err = http.Get(server.URL) err = %v; want %v`, err, nil)
 	}
 }
+
+func TestHTTPMiddleware(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterScoped[Service2](constainer, false)
+
+	middleware := di.HTTPMiddleware(constainer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := di.FromContext(r.Context())
+		if !ok {
+			t.Errorf(`c, ok := di.FromContext(r.Context()) ok = %v; want %v`, ok, true)
+		}
+		if c == nil {
+			t.Errorf(`c, ok := di.FromContext(r.Context()) c = %v; want %v`, c, di.Container{})
+		}
+
+		s2, err := di.Resolve[Service2](c)
+		if s2 == nil || err != nil {
+			t.Errorf(`s2, err := di.Resolve[Service2](c) = (%v, %v); want (%v, %v)`, s2, err, Service2{}, nil)
+		}
+
+		s2_2, err := di.ResolveFromContext[Service2](r.Context())
+		if s2_2 != s2 || err != nil {
+			t.Errorf(`di.ResolveFromContext[Service2](r.Context()) = (%v, %v); want (%v, %v)`, s2_2, err, s2, nil)
+		}
+	}))
+	server := httptest.NewServer(middleware)
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	if err != nil {
+		t.Errorf(`_, err = http.Get(server.URL) err = %v; want %v`, err, nil)
+	}
+}
+
+func TestMiddlewareDispose(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterScoped[DisposableService](constainer, false)
+
+	var scope *di.Container
+	var s *DisposableService
+	middleware := di.HTTPMiddleware(constainer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, _ = di.FromContext(r.Context())
+		s, _ = di.Resolve[DisposableService](scope)
+		panic("handler failed")
+	}))
+
+	func() {
+		defer func() { recover() }()
+		middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	if scope == nil || !scope.Disposed() {
+		t.Errorf("scope.Disposed() = %v; want %v", scope != nil && scope.Disposed(), true)
+	}
+	if s == nil || !s.disposed {
+		t.Errorf("s.disposed = %v; want %v", s != nil && s.disposed, true)
+	}
+}
+
+func TestResolveFromContext(t *testing.T) {
+	s, err := di.ResolveFromContext[Service1](context.Background())
+	if s != nil || err == nil {
+		t.Errorf("ResolveFromContext[Service1](context.Background()) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}
+
+type RequestUser struct {
+	name string
+}
+
+type RequestService struct {
+	request *http.Request        `di.inject:""`
+	writer  *http.ResponseWriter `di.inject:""`
+	ctx     *context.Context     `di.inject:""`
+	user    *RequestUser         `di.inject:""`
+}
+
+func TestMiddlewareScopeValues(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterScoped[RequestService](constainer, false)
+
+	middleware := di.HTTPMiddleware(constainer, di.WithScopeValues(func(r *http.Request) ([]any, error) {
+		return []any{&RequestUser{name: r.Header.Get("X-User")}}, nil
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := di.ResolveFromContext[RequestService](r.Context())
+		if s == nil || err != nil {
+			t.Fatalf("ResolveFromContext[RequestService](r.Context()) = %v,%v; want %v,%v", s, err, RequestService{}, nil)
+		}
+
+		if s.request != r {
+			t.Errorf("s.request = %v; want %v", s.request, r)
+		}
+		if *s.writer != w {
+			t.Errorf("*s.writer = %v; want %v", *s.writer, w)
+		}
+		if *s.ctx != r.Context() {
+			t.Errorf("*s.ctx = %v; want %v", *s.ctx, r.Context())
+		}
+		if s.user == nil || s.user.name != "alice" {
+			t.Errorf("s.user = %v; want %v", s.user, RequestUser{name: "alice"})
+		}
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-User", "alice")
+	middleware.ServeHTTP(httptest.NewRecorder(), r)
+}
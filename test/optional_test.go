@@ -0,0 +1,48 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type OptionalService struct {
+	service1 *Service1             `di.inject:",optional"`
+	cache    *Service2             `di.inject:"cache,optional"`
+	wrapped  di.Optional[Service2] `di.inject:""`
+	named    di.Optional[Service1] `di.inject:"test"`
+}
+
+type InvalidTagService struct {
+	service1 *Service1 `di.inject:",unknown"`
+}
+
+func TestOptional(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterByName(constainer, "test", &Service1{id: 1}, false)
+	di.RegisterTransient[OptionalService](constainer, false)
+	di.RegisterTransient[InvalidTagService](constainer, false)
+
+	s, err := di.Resolve[OptionalService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[OptionalService](constainer) = %v,%v; want %v,%v", s, err, OptionalService{}, nil)
+	}
+
+	if s.service1 == nil {
+		t.Errorf("s.service1 = %v; want %v", s.service1, Service1{})
+	}
+	if s.cache != nil {
+		t.Errorf("s.cache = %v; want %v", s.cache, nil)
+	}
+	if s2, ok := s.wrapped.Get(); s2 != nil || ok {
+		t.Errorf("s.wrapped.Get() = %v,%v; want %v,%v", s2, ok, nil, false)
+	}
+	if s1, ok := s.named.Get(); s1 == nil || !ok || s1.id != 1 {
+		t.Errorf("s.named.Get() = %v,%v; want %v,%v", s1, ok, Service1{id: 1}, true)
+	}
+
+	if s, err := di.Resolve[InvalidTagService](constainer); s != nil || err == nil {
+		t.Errorf("Resolve[InvalidTagService](constainer) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}
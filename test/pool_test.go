@@ -0,0 +1,123 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type PooledBuffer struct {
+	buf      bytes.Buffer
+	service  *Service1 `di.inject:""`
+	resets   int
+	disposed bool
+}
+
+func (b *PooledBuffer) Reset() {
+	b.buf.Reset()
+	b.service = nil
+	b.resets++
+}
+
+func (b *PooledBuffer) Dispose() error {
+	b.disposed = true
+	return nil
+}
+
+func TestPooled(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterPooled[PooledBuffer](constainer, false)
+
+	scope, _ := constainer.NewScope()
+	b1, err := di.Resolve[PooledBuffer](scope)
+	if b1 == nil || err != nil {
+		t.Fatalf("Resolve[PooledBuffer](scope) = %v,%v; want %v,%v", b1, err, PooledBuffer{}, nil)
+	}
+	if b1.service == nil {
+		t.Errorf("b1.service = %v; want %v", nil, "injected")
+	}
+	if b2, _ := di.Resolve[PooledBuffer](scope); b2 == b1 {
+		t.Errorf("Resolve[PooledBuffer](scope) = %p; want a different instance than %p", b2, b1)
+	}
+
+	b1.buf.WriteString("hello")
+	scope.Dispose()
+	if b1.disposed {
+		t.Errorf("b1.disposed = %v; want %v", true, false)
+	}
+
+	// sync.Pool may drop released instances, so reuse is not guaranteed.
+	other, _ := constainer.NewScope()
+	defer other.Dispose()
+	b3, err := di.Resolve[PooledBuffer](other)
+	if b3 == nil || err != nil {
+		t.Fatalf("Resolve[PooledBuffer](other) = %v,%v; want %v,%v", b3, err, PooledBuffer{}, nil)
+	}
+	if b3 == b1 && (b3.resets != 1 || b3.buf.Len() != 0 || b3.service == nil) {
+		t.Errorf("reused b3 = %+v; want reset with service injected", b3)
+	}
+}
+
+type PooledHolder struct {
+	buffer *PooledBuffer `di.inject:""`
+}
+
+func TestPooledCaptured(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterPooled[PooledBuffer](constainer, false)
+	di.RegisterSingleton[PooledHolder](constainer, false)
+
+	scope, _ := constainer.NewScope()
+	defer scope.Dispose()
+	if h, err := di.Resolve[PooledHolder](scope); h != nil || err == nil {
+		t.Errorf("Resolve[PooledHolder](scope) = %v,%v; want %v,%v", h, err, nil, "error")
+	}
+	if err := constainer.Validate(); err == nil {
+		t.Errorf("constainer.Validate() = %v; want %v", err, "error")
+	}
+}
+
+type PooledChain struct {
+	holder PooledTransient `di.inject:""`
+}
+
+type PooledTransient struct {
+	buffer *PooledBuffer `di.inject:""`
+}
+
+func TestPooledCapturedChain(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterPooled[PooledBuffer](constainer, false)
+	di.RegisterTransient[PooledTransient](constainer, false)
+	di.RegisterSingleton[PooledChain](constainer, false)
+
+	scope, _ := constainer.NewScope()
+	defer scope.Dispose()
+	if c, err := di.Resolve[PooledChain](scope); c != nil || err == nil {
+		t.Errorf("Resolve[PooledChain](scope) = %v,%v; want %v,%v", c, err, nil, "error")
+	}
+	if h, err := di.Resolve[PooledTransient](scope); h == nil || err != nil {
+		t.Errorf("Resolve[PooledTransient](scope) = %v,%v; want %v,%v", h, err, PooledTransient{}, nil)
+	}
+	if err := constainer.Validate(); err == nil {
+		t.Errorf("constainer.Validate() = %v; want %v", err, "error")
+	}
+}
+
+func TestPooledMaster(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterPooled[PooledBuffer](constainer, false)
+	di.RegisterScoped[PooledTransient](constainer, false)
+
+	if b, err := di.Resolve[PooledBuffer](constainer); b != nil || err == nil {
+		t.Errorf("Resolve[PooledBuffer](constainer) = %v,%v; want %v,%v", b, err, nil, "error")
+	}
+	if err := constainer.Validate(); err != nil {
+		t.Errorf("constainer.Validate() = %v; want %v", err, nil)
+	}
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type RequestBuilder struct {
+	baseURL string
+	headers map[string][]string
+	retry   *RetryPolicy
+	client  *Service1 `di.inject:""`
+}
+
+type RetryPolicy struct {
+	attempts int
+}
+
+type ClonedBuilder struct {
+	name   string
+	clones int
+}
+
+func (b *ClonedBuilder) Clone() *ClonedBuilder {
+	return &ClonedBuilder{name: b.name, clones: b.clones + 1}
+}
+
+func TestRegisterPrototype(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	template := &RequestBuilder{
+		baseURL: "https://api",
+		headers: map[string][]string{"Accept": {"application/json"}},
+		retry:   &RetryPolicy{attempts: 3},
+	}
+	di.RegisterPrototype(constainer, template, false)
+
+	b1, err := di.Resolve[RequestBuilder](constainer)
+	if b1 == nil || err != nil {
+		t.Fatalf("Resolve[RequestBuilder](constainer) = %v,%v; want %v,%v", b1, err, RequestBuilder{}, nil)
+	}
+	if b1 == template || b1.baseURL != "https://api" || b1.retry.attempts != 3 || b1.headers["Accept"][0] != "application/json" {
+		t.Errorf("b1 = %+v; want a copy of %+v", b1, template)
+	}
+	if b1.client == nil || template.client != nil {
+		t.Errorf("b1.client, template.client = %v,%v; want injected copy only", b1.client, template.client)
+	}
+
+	b1.headers["Accept"][0] = "text/plain"
+	b1.headers["X-Trace"] = []string{"1"}
+	b2, _ := di.Resolve[RequestBuilder](constainer)
+	if b2 == b1 || b2.headers["Accept"][0] != "application/json" || len(b2.headers) != 1 {
+		t.Errorf("b2.headers = %v; want a copy of %v", b2.headers, template.headers)
+	}
+	if b2.retry != template.retry {
+		t.Errorf("b2.retry = %p; want the template pointer %p", b2.retry, template.retry)
+	}
+
+	if err := di.RegisterPrototype[RetryPolicy](constainer, nil, true); err == nil {
+		t.Errorf("RegisterPrototype(constainer, nil) = %v; want %v", err, "error")
+	}
+}
+
+func TestRegisterPrototypeClone(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterPrototype(constainer, &ClonedBuilder{name: "orders"}, false)
+
+	b, err := di.Resolve[ClonedBuilder](constainer)
+	if b == nil || err != nil || b.name != "orders" || b.clones != 1 {
+		t.Errorf("Resolve[ClonedBuilder](constainer) = %+v,%v; want %+v,%v", b, err, ClonedBuilder{name: "orders", clones: 1}, nil)
+	}
+}
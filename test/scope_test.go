@@ -0,0 +1,201 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type DisposableService struct {
+	disposed bool
+}
+
+func (s *DisposableService) Dispose() error {
+	s.disposed = true
+	return nil
+}
+
+type ClosingService struct {
+	closed chan struct{}
+}
+
+func (s *ClosingService) Close() error {
+	close(s.closed)
+	return nil
+}
+
+type ContextService struct {
+	ctx *context.Context `di.inject:""`
+}
+
+func TestScopeDispose(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterScoped[DisposableService](constainer, false)
+
+	scope, _ := constainer.NewScope()
+	s, err := di.Resolve[DisposableService](scope)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[DisposableService](scope) = %v,%v; want %v,%v", s, err, DisposableService{}, nil)
+	}
+
+	if err := scope.Dispose(); err != nil {
+		t.Errorf("scope.Dispose() = %v; want %v", err, nil)
+	}
+	if !s.disposed {
+		t.Errorf("s.disposed = %v; want %v", s.disposed, true)
+	}
+
+	s, err = di.Resolve[DisposableService](scope)
+	if s != nil || err == nil {
+		t.Errorf("Resolve[DisposableService](scope) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}
+
+func TestScopeContext(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterFactory(constainer, di.Scoped, func(c di.Container) *ClosingService {
+		return &ClosingService{closed: make(chan struct{})}
+	}, false)
+	di.RegisterScoped[ContextService](constainer, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scope, err := constainer.NewScopeContext(ctx)
+	if scope == nil || err != nil {
+		t.Fatalf("constainer.NewScopeContext(ctx) = %v,%v; want %v,%v", scope, err, di.Container{}, nil)
+	}
+
+	if scope.Context() != ctx {
+		t.Errorf("scope.Context() = %v; want %v", scope.Context(), ctx)
+	}
+
+	cs, err := di.Resolve[ContextService](scope)
+	if cs == nil || err != nil {
+		t.Fatalf("Resolve[ContextService](scope) = %v,%v; want %v,%v", cs, err, ContextService{}, nil)
+	}
+	if *cs.ctx != ctx {
+		t.Errorf("*cs.ctx = %v; want %v", *cs.ctx, ctx)
+	}
+
+	s, _ := di.Resolve[ClosingService](scope)
+	cancel()
+
+	select {
+	case <-s.closed:
+	case <-time.After(time.Second):
+		t.Error("scope was not disposed after its context was canceled")
+	}
+
+	if !scope.Disposed() {
+		t.Errorf("scope.Disposed() = %v; want %v", scope.Disposed(), true)
+	}
+}
+
+func TestScopeProvide(t *testing.T) {
+	constainer := di.NewContainer()
+	root := &Service1{id: 1}
+	di.RegisterInstance(constainer, root, false)
+	di.RegisterScoped[Service5](constainer, false)
+
+	scope, _ := constainer.NewScope()
+	other, _ := constainer.NewScope()
+
+	local := &Service1{id: 2}
+	if err := di.Provide(scope, local); err != nil {
+		t.Fatalf("Provide(scope, local) = %v; want %v", err, nil)
+	}
+	if err := di.Provide(scope, &Service1{id: 3}); err == nil {
+		t.Errorf("Provide(scope, &Service1{}) = %v; want %v", err, "error")
+	}
+
+	s5, err := di.Resolve[Service5](scope)
+	if s5 == nil || err != nil || s5.service1 != local {
+		t.Fatalf("Resolve[Service5](scope) = %v,%v; want service1 %v", s5, err, local)
+	}
+
+	child, err := scope.NewScope()
+	if child == nil || err != nil {
+		t.Fatalf("scope.NewScope() = %v,%v; want %v,%v", child, err, di.Container{}, nil)
+	}
+	if s1, _ := di.Resolve[Service1](child); s1 != local {
+		t.Errorf("Resolve[Service1](child) = %v; want %v", s1, local)
+	}
+	if s1, _ := di.Resolve[Service1](other); s1 != root {
+		t.Errorf("Resolve[Service1](other) = %v; want %v", s1, root)
+	}
+	if s1, _ := di.Resolve[Service1](constainer); s1 != root {
+		t.Errorf("Resolve[Service1](constainer) = %v; want %v", s1, root)
+	}
+
+	childLocal := &Service1{id: 4}
+	if err := di.Provide(child, childLocal); err != nil {
+		t.Errorf("Provide(child, childLocal) = %v; want %v", err, nil)
+	}
+	if s1, _ := di.Resolve[Service1](scope); s1 != local {
+		t.Errorf("Resolve[Service1](scope) = %v; want %v", s1, local)
+	}
+
+	overridden := &Service1{id: 5}
+	if err := di.Override(scope, overridden); err != nil {
+		t.Errorf("Override(scope, overridden) = %v; want %v", err, nil)
+	}
+	if s1, _ := di.Resolve[Service1](scope); s1 != overridden {
+		t.Errorf("Resolve[Service1](scope) = %v; want %v", s1, overridden)
+	}
+
+	if err := di.RegisterInstance(scope, &Service2{}, true); err == nil {
+		t.Errorf("RegisterInstance(scope, &Service2{}, true) = %v; want %v", err, "error")
+	}
+	if err := di.Provide(constainer, &Service2{}); err == nil {
+		t.Errorf("Provide(constainer, &Service2{}) = %v; want %v", err, "error")
+	}
+}
+
+func TestScopeProvideDispose(t *testing.T) {
+	constainer := di.NewContainer()
+	scope, _ := constainer.NewScope()
+	child, _ := scope.NewScope()
+
+	s := &DisposableService{}
+	di.Provide(child, s)
+
+	scope.Dispose()
+	if !child.Disposed() || !s.disposed {
+		t.Errorf("child.Disposed(), s.disposed = %v,%v; want %v,%v", child.Disposed(), s.disposed, true, true)
+	}
+}
+
+func TestMasterTransientNotDisposed(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterTransient[DisposableService](constainer, false)
+
+	s, err := di.Resolve[DisposableService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[DisposableService](constainer) = %v,%v; want %v,%v", s, err, DisposableService{}, nil)
+	}
+	if err := constainer.Dispose(); err != nil {
+		t.Fatalf("constainer.Dispose() = %v; want %v", err, nil)
+	}
+	if s.disposed {
+		t.Errorf("transient resolved from the master container was disposed")
+	}
+}
+
+func TestNestedScopeDisposeEarly(t *testing.T) {
+	constainer := di.NewContainer()
+	scope, _ := constainer.NewScope()
+
+	for i := 0; i < 3; i++ {
+		child, err := scope.NewScope()
+		if err != nil {
+			t.Fatalf("scope.NewScope() = %v,%v; want a scope,%v", child, err, nil)
+		}
+		if err := child.Dispose(); err != nil {
+			t.Fatalf("child.Dispose() = %v; want %v", err, nil)
+		}
+	}
+	if err := scope.Dispose(); err != nil {
+		t.Errorf("scope.Dispose() = %v; want %v", err, nil)
+	}
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type DBCredentials struct {
+	User     di.Secret `di.secret:"db/user"`
+	Password di.Secret `di.secret:"db/password"`
+	Token    di.Secret `di.secret:"api/token,optional"`
+}
+
+type MissingSecretService struct {
+	token di.Secret `di.secret:"api/token"`
+}
+
+func TestSecrets(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "db"), 0o700)
+	os.WriteFile(filepath.Join(dir, "db", "password"), []byte("s3cr3t\n"), 0o600)
+	t.Setenv("TEST_DB_USER", "admin")
+
+	constainer := di.NewContainer(di.WithSecretProviders(
+		di.NewFileSecretProvider(dir),
+		di.NewEnvSecretProvider("test"),
+	))
+	di.RegisterSingleton[DBCredentials](constainer, false)
+	di.RegisterTransient[MissingSecretService](constainer, false)
+
+	creds, err := di.Resolve[DBCredentials](constainer)
+	if creds == nil || err != nil {
+		t.Fatalf("Resolve[DBCredentials](constainer) = %v,%v; want %v,%v", creds, err, DBCredentials{}, nil)
+	}
+	if creds.User.Reveal() != "admin" || creds.Password.Reveal() != "s3cr3t" || creds.Token.Reveal() != "" {
+		t.Errorf("creds = %q,%q,%q; want %q,%q,%q", creds.User.Reveal(), creds.Password.Reveal(), creds.Token.Reveal(), "admin", "s3cr3t", "")
+	}
+
+	data, _ := json.Marshal(creds)
+	unexported := struct{ password di.Secret }{password: creds.Password}
+	outputs := []string{
+		fmt.Sprint(creds), fmt.Sprintf("%+v", creds), fmt.Sprintf("%#v", creds),
+		fmt.Sprintf("%+v", unexported), fmt.Sprintf("%#v", unexported),
+		fmt.Sprintf("%s", creds.Password), fmt.Sprintf("%q", creds.Password), fmt.Sprintf("%x", creds.Password),
+		string(data),
+	}
+	for _, out := range outputs {
+		if strings.Contains(out, "s3cr3t") || strings.Contains(out, "admin") || strings.Contains(out, fmt.Sprintf("%x", "s3cr3t")) {
+			t.Errorf("output %q reveals a secret", out)
+		}
+	}
+
+	if s, err := di.Resolve[MissingSecretService](constainer); s != nil || err == nil {
+		t.Errorf("Resolve[MissingSecretService](constainer) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}
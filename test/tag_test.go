@@ -0,0 +1,105 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ns-go/di/pkg/di"
+)
+
+type TagService struct {
+	service1 *Service1 `inject:""`
+	named    *Service1 `inject:"test"`
+	skipped  *Service1 `inject:"-"`
+	other    *Service2 `doc:"di.inject:"`
+}
+
+func TestTagKey(t *testing.T) {
+	constainer := di.NewContainer(di.WithTagKey("inject"))
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterByName(constainer, "test", &Service1{id: 1}, false)
+	di.RegisterTransient[TagService](constainer, false)
+
+	s, err := di.Resolve[TagService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[TagService](constainer) = %v,%v; want %v,%v", s, err, TagService{}, nil)
+	}
+
+	if s.service1 == nil {
+		t.Errorf("s.service1 = %v; want %v", s.service1, Service1{})
+	}
+	if s.named == nil || s.named.id != 1 {
+		t.Errorf("s.named = %v; want %v", s.named, Service1{id: 1})
+	}
+	if s.skipped != nil || s.other != nil {
+		t.Errorf("s.skipped, s.other = %v,%v; want %v,%v", s.skipped, s.other, nil, nil)
+	}
+
+	scope, _ := constainer.NewScope()
+	if s, err := di.Resolve[TagService](scope); s == nil || s.service1 == nil || err != nil {
+		t.Errorf("Resolve[TagService](scope) = %v,%v; want %v,%v", s, err, TagService{}, nil)
+	}
+}
+
+func TestTagOtherKeyValue(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterTransient[TagService](constainer, false)
+
+	// Other tags whose value contains "di.inject:" are not injection tags.
+	s, err := di.Resolve[TagService](constainer)
+	if s == nil || err != nil {
+		t.Errorf("Resolve[TagService](constainer) = %v,%v; want %v,%v", s, err, TagService{}, nil)
+	}
+}
+
+type LazyTagService struct {
+	service1 func() *Service1          `di.inject:",lazy"`
+	service2 func() (*Service2, error) `di.inject:",lazy"`
+	missing  func() (*Service3, error) `di.inject:",lazy"`
+	lazy     di.Lazy[Service1]         `di.inject:",lazy"`
+	greeters []Greeter                 `di.inject:",group"`
+}
+
+func TestTagLazyGroup(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterSingleton[Service1](constainer, false)
+	di.RegisterSingleton[Service2](constainer, false)
+	di.RegisterSingleton[EnglishGreeter](constainer, false)
+	di.RegisterSingleton[FrenchGreeter](constainer, false)
+	di.RegisterTransient[LazyTagService](constainer, false)
+
+	s, err := di.Resolve[LazyTagService](constainer)
+	if s == nil || err != nil {
+		t.Fatalf("Resolve[LazyTagService](constainer) = %v,%v; want %v,%v", s, err, LazyTagService{}, nil)
+	}
+
+	s1, _ := di.Resolve[Service1](constainer)
+	if got := s.service1(); got != s1 {
+		t.Errorf("s.service1() = %p; want %p", got, s1)
+	}
+	if got, err := s.service2(); got == nil || err != nil {
+		t.Errorf("s.service2() = %v,%v; want %v,%v", got, err, Service2{}, nil)
+	}
+	if got, err := s.missing(); got != nil || err == nil {
+		t.Errorf("s.missing() = %v,%v; want %v,%v", got, err, nil, "error")
+	}
+	if got, err := s.lazy.Get(); got != s1 || err != nil {
+		t.Errorf("s.lazy.Get() = %v,%v; want %v,%v", got, err, s1, nil)
+	}
+
+	if len(s.greeters) != 2 || s.greeters[0].Greet() != "hello" || s.greeters[1].Greet() != "bonjour" {
+		t.Errorf("s.greeters = %v; want %v", s.greeters, []Greeter{&EnglishGreeter{}, &FrenchGreeter{}})
+	}
+}
+
+type InvalidGroupService struct {
+	greeters []Greeter `di.inject:"english,group"`
+}
+
+func TestTagGroupInvalid(t *testing.T) {
+	constainer := di.NewContainer()
+	di.RegisterTransient[InvalidGroupService](constainer, false)
+
+	if s, err := di.Resolve[InvalidGroupService](constainer); s != nil || err == nil {
+		t.Errorf("Resolve[InvalidGroupService](constainer) = %v,%v; want %v,%v", s, err, nil, "error")
+	}
+}